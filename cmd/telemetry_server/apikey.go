@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// createdApiKey is printed when a key is created; the secret is not shown again
+type createdApiKey struct {
+	types.ApiKey
+	Key string `json:"key"`
+}
+
+func parseApiKeyID(arg string) (int64, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid api key id %q", arg)
+	}
+	return id, nil
+}
+
+// apikey-create <name> <scope>...
+func apiKeyCreateCommand(ctx context.Context, env *commandEnv, args []string) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+
+	key, secret, err := core.CreateApiKey(ctx, env.ps, func() (int64, error) { return env.GenerateID(ctx) }, args[0], args[1:])
+	if err != nil {
+		return err
+	}
+	env.recordAudit(ctx, types.AuditApiKeyCreate, core.ApiKeyAuditTarget(key.ID), nil, key)
+
+	log.Info().Int64("id", key.ID).Str("name", key.Name).Strs("scopes", key.Scopes).Msg("API key created")
+	return json.NewEncoder(os.Stdout).Encode(createdApiKey{ApiKey: key, Key: secret})
+}
+
+// apikey-list
+func apiKeyListCommand(ctx context.Context, env *commandEnv, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	keys, err := env.ps.ApiKeyList(ctx)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	for _, key := range keys {
+		if err := enc.Encode(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apikey-revoke <id>
+func apiKeyRevokeCommand(ctx context.Context, env *commandEnv, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	id, err := parseApiKeyID(args[0])
+	if err != nil {
+		return err
+	}
+
+	before, after, err := core.RevokeApiKey(ctx, env.ps, id)
+	if err != nil {
+		return fmt.Errorf("api key %d: %w", id, err)
+	}
+	env.recordAudit(ctx, types.AuditApiKeyRevoke, core.ApiKeyAuditTarget(id), before, after)
+
+	log.Info().Int64("id", id).Msg("API key revoked")
+	return nil
+}
+
+// apikey-rotate <id>
+//
+// Creates a key with the name and scopes of an active key, then revokes the old key.
+func apiKeyRotateCommand(ctx context.Context, env *commandEnv, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	id, err := parseApiKeyID(args[0])
+	if err != nil {
+		return err
+	}
+
+	old, key, secret, err := core.RotateApiKey(ctx, env.ps, func() (int64, error) { return env.GenerateID(ctx) }, id)
+	if key.ID != 0 {
+		env.recordAudit(ctx, types.AuditApiKeyRotate, core.ApiKeyAuditTarget(id), old, key)
+		log.Info().Int64("id", key.ID).Str("name", key.Name).Strs("scopes", key.Scopes).Msg("API key created")
+		if err := json.NewEncoder(os.Stdout).Encode(createdApiKey{ApiKey: key, Key: secret}); err != nil {
+			return err
+		}
+	}
+	if err != nil {
+		// a new key is still printed if only revoking the old one failed
+		return err
+	}
+
+	log.Info().Int64("id", id).Msg("API key revoked")
+	return nil
+}
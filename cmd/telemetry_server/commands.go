@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.eu.org/envloader"
+	"gosuda.org/randflake"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/persistence"
+	"telemetry.gosuda.org/telemetry/internal/server"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+var errUsage = errors.New("invalid arguments")
+
+// command is a maintenance subcommand of telemetry_server
+type command struct {
+	usage string
+	run   func(ctx context.Context, env *commandEnv, args []string) error
+}
+
+var _commands = map[string]command{
+	"alias-url":     {usage: "alias-url <alias> <canonical>", run: aliasURLCommand},
+	"apikey-create": {usage: "apikey-create <name> <scope>... (scopes: read:stats, write:moderation, admin)", run: apiKeyCreateCommand},
+	"apikey-list":   {usage: "apikey-list", run: apiKeyListCommand},
+	"apikey-revoke": {usage: "apikey-revoke <id>", run: apiKeyRevokeCommand},
+	"apikey-rotate": {usage: "apikey-rotate <id>", run: apiKeyRotateCommand},
+	"dedupe-urls":   {usage: "dedupe-urls", run: dedupeURLsCommand},
+	"merge-urls":    {usage: "merge-urls <from> <into>", run: mergeURLsCommand},
+	"reconcile":     {usage: "reconcile [-fix] [-batch-size n] [-batch-delay d]", run: reconcileCommand},
+}
+
+// commandEnv holds the services available to commands
+type commandEnv struct {
+	ps        *persistence.PersistenceClient
+	config    *server.ServerConfig
+	generator *randflake.Generator
+}
+
+// GenerateID generates a new randflake ID, leasing a node ID on first use
+func (e *commandEnv) GenerateID(ctx context.Context) (int64, error) {
+	if e.generator == nil {
+		g, err := server.NewLeasedGenerator(ctx, e.ps, e.config.RandflakeSecret)
+		if err != nil {
+			return 0, err
+		}
+		e.generator = g
+	}
+	return e.generator.Generate()
+}
+
+// recordAudit appends an action of a command to the audit log. The action has already
+// been performed, so failures are logged rather than returned.
+func (e *commandEnv) recordAudit(ctx context.Context, action string, target string, before any, after any) {
+	generateID := func() (int64, error) { return e.GenerateID(ctx) }
+	err := core.RecordAudit(ctx, e.ps, generateID, types.AuditActorMaintenance, action, target, before, after)
+	if err != nil {
+		log.Error().Err(err).Str("action", action).Str("target", target).Msg("Failed to record audit event")
+	}
+}
+
+func printUsage() {
+	names := make([]string, 0, len(_commands))
+	for name := range _commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "usage: telemetry_server [command]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n", _commands[name].usage)
+	}
+}
+
+// runCommand runs a maintenance command and returns the process exit code
+func runCommand(name string, args []string) int {
+	cmd, ok := _commands[name]
+	if !ok {
+		printUsage()
+		return 2
+	}
+
+	config := &server.ServerConfig{}
+	err := envloader.BindStruct(config, configProvider)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to bind server config")
+	}
+
+	ps := newPersistenceClient()
+	defer ps.Close()
+
+	err = cmd.run(context.Background(), &commandEnv{ps: ps, config: config}, args)
+	if err == errUsage {
+		fmt.Fprintln(os.Stderr, "usage: telemetry_server", cmd.usage)
+		return 2
+	}
+	if err != nil {
+		log.Error().Err(err).Str("command", name).Msg("Command failed")
+		return 1
+	}
+	return 0
+}
@@ -37,27 +37,14 @@ func init() {
 	}()
 }
 
-func main() {
-	envloader.LoadEnvFile(".env")
-	configProvider := func(name string) (string, error) {
-		if value, ok := os.LookupEnv(name); ok {
-			return value, nil
-		}
-		return "", fmt.Errorf("environment variable %s not found", name)
-	}
-
-	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
-	zerolog.SetGlobalLevel(zerolog.DebugLevel)
-
-	ln, err := net.Listen("tcp", ":0")
-	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to listen on port")
+func configProvider(name string) (string, error) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, nil
 	}
-	defer ln.Close()
-	fmt.Println("{\"port\":", ln.Addr().(*net.TCPAddr).Port, "}")
-
-	log.Info().Msgf("Server starting on port %d", ln.Addr().(*net.TCPAddr).Port)
+	return "", fmt.Errorf("environment variable %s not found", name)
+}
 
+func newPersistenceClient() *persistence.PersistenceClient {
 	dbconfig := &persistence.PersistenceClientConfig{
 		DSN:             "root@localhost/database",
 		ConnMaxIdleTime: time.Minute * 4,
@@ -65,7 +52,7 @@ func main() {
 		MaxIdleConns:    5,
 		MaxOpenConns:    0,
 	}
-	err = envloader.BindStruct(dbconfig, configProvider)
+	err := envloader.BindStruct(dbconfig, configProvider)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to bind database config")
 	}
@@ -74,6 +61,29 @@ func main() {
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to create persistence client")
 	}
+	return ps
+}
+
+func main() {
+	envloader.LoadEnvFile(".env")
+
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to listen on port")
+	}
+	defer ln.Close()
+	fmt.Println("{\"port\":", ln.Addr().(*net.TCPAddr).Port, "}")
+
+	log.Info().Msgf("Server starting on port %d", ln.Addr().(*net.TCPAddr).Port)
+
+	ps := newPersistenceClient()
 
 	srvConfig := &server.ServerConfig{
 		PersistenceService: ps,
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// reconcile [-fix] [-batch-size n] [-batch-delay d]
+func reconcileCommand(ctx context.Context, env *commandEnv, args []string) error {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	fix := flags.Bool("fix", false, "rewrite drifted counters")
+	batchSize := flags.Int("batch-size", 500, "URLs checked per query")
+	batchDelay := flags.Duration("batch-delay", 0, "pause between batches")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 || *batchSize <= 0 {
+		return errUsage
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	var encErr error
+	result, err := core.ReconcileCounts(
+		ctx,
+		env.ps,
+		func() (int64, error) { return env.GenerateID(ctx) },
+		core.ReconcileOptions{
+			BatchSize:  int32(*batchSize),
+			BatchDelay: *batchDelay,
+			Fix:        *fix,
+		},
+		func(c types.CountCheck) {
+			if encErr == nil {
+				encErr = enc.Encode(c)
+			}
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if encErr != nil {
+		return encErr
+	}
+
+	log.Info().
+		Int64("checked", result.Checked).
+		Int64("discrepancies", result.Discrepancies).
+		Int64("fixed", result.Fixed).
+		Msg("Counts reconciled")
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// normalizeArgs canonicalizes URL arguments with the configured URL rules
+func normalizeArgs(env *commandEnv, args []string) ([]string, error) {
+	rules, err := core.LoadURLCanonicalizer(env.config.URLRulesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0, len(args))
+	for _, arg := range args {
+		n, err := rules.NormalizeURL(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", arg, err)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// alias-url <alias> <canonical>
+func aliasURLCommand(ctx context.Context, env *commandEnv, args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+
+	urls, err := normalizeArgs(env, args)
+	if err != nil {
+		return err
+	}
+
+	canonical, err := env.ps.UrlLookupByUrl(ctx, urls[1])
+	if err != nil {
+		return fmt.Errorf("canonical url %s: %w", urls[1], err)
+	}
+
+	id, err := env.GenerateID(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = env.ps.UrlAliasCreate(ctx, id, urls[0], canonical.ID)
+	if err != nil {
+		return err
+	}
+	env.recordAudit(ctx, types.AuditUrlAlias, urls[0], nil, canonical.Url)
+
+	log.Info().Str("alias", urls[0]).Str("url", canonical.Url).Msg("URL alias created")
+	return nil
+}
+
+// merge-urls <from> <into>
+func mergeURLsCommand(ctx context.Context, env *commandEnv, args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+
+	urls, err := normalizeArgs(env, args)
+	if err != nil {
+		return err
+	}
+
+	from, err := env.ps.UrlLookupByUrl(ctx, urls[0])
+	if err != nil {
+		return fmt.Errorf("source url %s: %w", urls[0], err)
+	}
+	into, err := env.ps.UrlLookupByUrl(ctx, urls[1])
+	if err != nil {
+		return fmt.Errorf("target url %s: %w", urls[1], err)
+	}
+
+	aliasID, err := env.GenerateID(ctx)
+	if err != nil {
+		return err
+	}
+
+	result, err := env.ps.UrlMerge(ctx, from.ID, into.ID, aliasID)
+	if err != nil {
+		return err
+	}
+	env.recordAudit(ctx, types.AuditUrlMerge, result.FromURL, nil, result)
+
+	return json.NewEncoder(os.Stdout).Encode(result)
+}
+
+// dedupe-urls
+func dedupeURLsCommand(ctx context.Context, env *commandEnv, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	urls, err := env.ps.UrlListDuplicates(ctx)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	for _, url := range urls {
+		records, err := env.ps.UrlListByUrl(ctx, url)
+		if err != nil {
+			return err
+		}
+
+		// keep the oldest record, merge the rest into it
+		for _, dup := range records[1:] {
+			aliasID, err := env.GenerateID(ctx)
+			if err != nil {
+				return err
+			}
+
+			result, err := env.ps.UrlMerge(ctx, dup.ID, records[0].ID, aliasID)
+			if err != nil {
+				return fmt.Errorf("merge duplicate of %s: %w", url, err)
+			}
+			env.recordAudit(ctx, types.AuditUrlMerge, result.FromURL, nil, result)
+			if err := enc.Encode(result); err != nil {
+				return err
+			}
+		}
+	}
+
+	updated, err := env.ps.UrlBackfillHashes(ctx)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Int("duplicates", len(urls)).Int64("hashes_updated", updated).Msg("URL records deduplicated")
+	return nil
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+type adminKeyContextKey struct{}
+
+// registerAdminRoutes registers the /admin/v1 routes, which require an API key
+// created with the apikey-create command
+func registerAdminRoutes(s *httprouter.Router, is types.InternalServiceProvider) {
+	s.Handle("GET", "/admin/v1/key", RequireScope(is, "", AdminKeyHandler(is)))
+	s.Handle("GET", "/admin/v1/url", RequireScope(is, types.ScopeReadStats, AdminURLHandler(is)))
+	s.Handle("GET", "/admin/v1/errors", RequireScope(is, types.ScopeReadStats, AdminErrorsHandler(is)))
+	s.Handle("POST", "/admin/v1/views/purge", RequireScope(is, types.ScopeWriteModeration, PurgeViewsHandler(is)))
+	s.Handle("POST", "/admin/v1/likes/purge", RequireScope(is, types.ScopeWriteModeration, PurgeLikesHandler(is)))
+	s.Handle("PUT", "/admin/v1/offset", RequireScope(is, types.ScopeWriteModeration, CountOffsetHandler(is)))
+	s.Handle("GET", "/admin/v1/blocklist", RequireScope(is, types.ScopeWriteModeration, BlocklistListHandler(is)))
+	s.Handle("POST", "/admin/v1/blocklist", RequireScope(is, types.ScopeWriteModeration, BlocklistAddHandler(is)))
+	s.Handle("DELETE", "/admin/v1/blocklist/:id", RequireScope(is, types.ScopeWriteModeration, BlocklistRemoveHandler(is)))
+	s.Handle("GET", "/admin/v1/keys", RequireScope(is, types.ScopeAdmin, ApiKeyListHandler(is)))
+	s.Handle("POST", "/admin/v1/keys/:id/rotate", RequireScope(is, types.ScopeAdmin, ApiKeyRotateHandler(is)))
+	s.Handle("DELETE", "/admin/v1/keys/:id", RequireScope(is, types.ScopeAdmin, ApiKeyRevokeHandler(is)))
+	s.Handle("GET", "/admin/v1/audit", RequireScope(is, types.ScopeAdmin, AuditListHandler(is)))
+	s.Handle("GET", "/admin/v1/audit/export", RequireScope(is, types.ScopeAdmin, AuditExportHandler(is)))
+	s.Handle("GET", "/admin/v1/idz", RequireScope(is, types.ScopeAdmin, IDzHandler(is)))
+	s.Handle("GET", "/admin/v1/getz", RequireScope(is, types.ScopeAdmin, GetzHandler(is)))
+}
+
+// RequireScope wraps next so it only runs for requests with an active API key in an
+// "Authorization: Bearer <key>" header granting scope. An empty scope accepts any
+// active key. The key is available to next through AdminKey.
+func RequireScope(is types.InternalServiceProvider, scope string, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		w.Header().Set("Cache-Control", "no-store")
+
+		token, ok := core.BearerToken(r.Header.Get("Authorization"))
+		if !ok {
+			writeAdminError(w, http.StatusUnauthorized, "api key required")
+			return
+		}
+
+		key, found, err := is.ApiKeyLookupByHash(r.Context(), core.ApiKeyHash(token))
+		if err != nil {
+			log.Error().Err(err).Msg("failed to look up api key")
+			writeAdminError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		if !found || key.RevokedAt != 0 {
+			writeAdminError(w, http.StatusUnauthorized, "invalid api key")
+			return
+		}
+		if scope != "" && !key.HasScope(scope) {
+			writeAdminError(w, http.StatusForbidden, "api key lacks scope "+scope)
+			return
+		}
+
+		if err := is.ApiKeyTouch(r.Context(), key.ID); err != nil {
+			log.Warn().Err(err).Int64("api_key_id", key.ID).Msg("failed to record api key use")
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), adminKeyContextKey{}, key)), ps)
+	}
+}
+
+// AdminKey returns the API key that authenticated r
+func AdminKey(r *http.Request) (types.ApiKey, bool) {
+	key, ok := r.Context().Value(adminKeyContextKey{}).(types.ApiKey)
+	return key, ok
+}
+
+func writeAdminError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// GET /admin/v1/key
+//
+// Returns the API key making the request, to check a key and its scopes.
+func AdminKeyHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		key, _ := AdminKey(r)
+		json.NewEncoder(w).Encode(key)
+	}
+}
+
+// AdminURLResponse describes a stored URL and its counters. The counts include the offsets.
+type AdminURLResponse struct {
+	ID        int64  `json:"id,string"`
+	URL       string `json:"url"`
+	ViewCount int64  `json:"view_count"`
+	LikeCount int64  `json:"like_count"`
+	types.CountOffset
+}
+
+// GET /admin/v1/url?url=<url>
+//
+// Returns the ID and counters of a URL. Requires the read:stats scope.
+func AdminURLHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+
+		urlRecord, ok := lookupStatsURL(is, w, r)
+		if !ok {
+			return
+		}
+
+		counts, err := is.BulkCountsByUrls(r.Context(), []string{urlRecord.Url})
+		if err != nil {
+			log.Error().Err(err).Str("url", urlRecord.Url).Msg("failed to look up counts")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "internal error"})
+			return
+		}
+		offset, err := is.CountOffsetLookup(r.Context(), urlRecord.ID)
+		if err != nil {
+			log.Error().Err(err).Str("url", urlRecord.Url).Msg("failed to look up count offset")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "internal error"})
+			return
+		}
+
+		response := AdminURLResponse{ID: urlRecord.ID, URL: urlRecord.Url, CountOffset: offset}
+		for _, c := range counts {
+			response.ViewCount, response.LikeCount = c.ViewCount, c.LikeCount
+		}
+		json.NewEncoder(w).Encode(response)
+	}
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// ApiKeysResponse lists API keys, including revoked ones
+type ApiKeysResponse struct {
+	Keys []types.ApiKey `json:"keys"`
+}
+
+// RotatedApiKeyResponse carries a new API key and its secret, which is not shown again.
+// Warning is set if the old key is still active, and Error if the rotation could not be
+// recorded in the audit log.
+type RotatedApiKeyResponse struct {
+	types.ApiKey
+	Key     string `json:"key"`
+	Warning string `json:"warning,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// parseApiKeyParam reads the :id parameter. It writes an error response and returns
+// false if it is invalid.
+func parseApiKeyParam(w http.ResponseWriter, ps httprouter.Params) (int64, bool) {
+	id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// GET /admin/v1/keys
+//
+// Lists all API keys without their secrets. Requires the admin scope.
+func ApiKeyListHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+
+		keys, err := is.ApiKeyList(r.Context())
+		if err != nil {
+			log.Error().Err(err).Msg("failed to list api keys")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "internal error"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(ApiKeysResponse{Keys: keys})
+	}
+}
+
+// POST /admin/v1/keys/:id/rotate
+//
+// Creates a key with the name and scopes of an active key, then revokes the old key.
+// Returns the new key with its secret. Requires the admin scope.
+func ApiKeyRotateHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, ok := parseApiKeyParam(w, ps)
+		if !ok {
+			return
+		}
+
+		old, key, secret, err := core.RotateApiKey(r.Context(), is, is.GenerateID, id)
+		if key.ID == 0 {
+			if errors.Is(err, core.ErrApiKeyNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(map[string]string{"error": "api key not found or already revoked"})
+				return
+			}
+			log.Error().Err(err).Int64("api_key_id", id).Msg("failed to rotate api key")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "internal error"})
+			return
+		}
+
+		// the new key works even if the old one could not be revoked or the rotation could
+		// not be audited, so it is returned rather than lost
+		response := RotatedApiKeyResponse{ApiKey: key, Key: secret}
+		if err != nil {
+			log.Error().Err(err).Int64("api_key_id", id).Msg("failed to revoke rotated api key")
+			response.Warning = "the old key could not be revoked"
+		}
+
+		status := http.StatusCreated
+		if auditAction(is, r, types.AuditApiKeyRotate, core.ApiKeyAuditTarget(id), old, key) != nil {
+			status = http.StatusInternalServerError
+			response.Error = "the key was rotated but the rotation could not be recorded in the audit log"
+		}
+
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// DELETE /admin/v1/keys/:id
+//
+// Revokes an API key and returns it. Requires the admin scope.
+func ApiKeyRevokeHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, ok := parseApiKeyParam(w, ps)
+		if !ok {
+			return
+		}
+
+		before, after, err := core.RevokeApiKey(r.Context(), is, id)
+		if errors.Is(err, core.ErrApiKeyNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "api key not found or already revoked"})
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Int64("api_key_id", id).Msg("failed to revoke api key")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "internal error"})
+			return
+		}
+
+		if !recordAudit(is, w, r, types.AuditApiKeyRevoke, core.ApiKeyAuditTarget(id), before, after) {
+			return
+		}
+		json.NewEncoder(w).Encode(after)
+	}
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+const (
+	_AUDIT_DEFAULT_LIMIT = 100
+	_AUDIT_MAX_LIMIT     = 1000
+)
+
+// recordAudit appends an admin action by the API key of r to the audit log. The action
+// has already been performed, but an unaudited admin change is not acceptable, so if
+// recording fails recordAudit writes an internal error response and returns false.
+func recordAudit(is types.InternalServiceProvider, w http.ResponseWriter, r *http.Request, action string, target string, before any, after any) bool {
+	if err := auditAction(is, r, action, target, before, after); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "the change was applied but could not be recorded in the audit log"})
+		return false
+	}
+	return true
+}
+
+// auditAction appends an admin action by the API key of r to the audit log, logging
+// failures before returning them
+func auditAction(is types.InternalServiceProvider, r *http.Request, action string, target string, before any, after any) error {
+	key, _ := AdminKey(r)
+	err := core.RecordAudit(context.WithoutCancel(r.Context()), is, is.GenerateID, key.ID, action, target, before, after)
+	if err != nil {
+		log.Error().Err(err).
+			Int64("api_key_id", key.ID).
+			Str("action", action).
+			Str("target", target).
+			Msg("failed to record audit event")
+	}
+	return err
+}
+
+// AuditResponse is a page of audit events, newest first. NextCursor is set when
+// there may be more events.
+type AuditResponse struct {
+	Events     []types.AuditEvent `json:"events"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+// parseAuditFilter reads the from, to, actor, action, target and cursor query
+// parameters. It writes an error response and returns false if they are invalid.
+func parseAuditFilter(w http.ResponseWriter, r *http.Request) (types.AuditFilter, bool) {
+	q := r.URL.Query()
+
+	from, to, err := parseTimeRange(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return types.AuditFilter{}, false
+	}
+
+	filter := types.AuditFilter{
+		From:            from,
+		To:              to,
+		AnyActor:        true,
+		Action:          q.Get("action"),
+		Target:          q.Get("target"),
+		CursorCreatedAt: math.MaxInt64,
+	}
+	if v := q.Get("actor"); v != "" {
+		filter.ActorKeyID, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid actor"})
+			return types.AuditFilter{}, false
+		}
+		filter.AnyActor = false
+	}
+	if v := q.Get("cursor"); v != "" {
+		if err := core.DecodeAuditCursor(v, &filter); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid cursor"})
+			return types.AuditFilter{}, false
+		}
+	}
+	return filter, true
+}
+
+// GET /admin/v1/audit?from=&to=&actor=&action=&target=&limit=&cursor=
+//
+// Lists audit events newest first, optionally filtered by time range, actor API key
+// ID (0 for maintenance commands), action and exact target. Pass next_cursor as cursor
+// to fetch the next page. Requires the admin scope.
+func AuditListHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+
+		filter, ok := parseAuditFilter(w, r)
+		if !ok {
+			return
+		}
+		limit := parseLimit(r, _AUDIT_DEFAULT_LIMIT, _AUDIT_MAX_LIMIT)
+
+		events, err := is.AuditList(r.Context(), filter, int32(limit))
+		if err != nil {
+			log.Error().Err(err).Msg("failed to list audit events")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "internal error"})
+			return
+		}
+
+		response := AuditResponse{Events: events}
+		if len(events) == limit {
+			response.NextCursor = core.EncodeAuditCursor(events[len(events)-1])
+		}
+		json.NewEncoder(w).Encode(response)
+	}
+}
+
+// GET /admin/v1/audit/export?from=&to=&actor=&action=&target=
+//
+// Streams all audit events matching the filters of AuditListHandler as JSON lines,
+// newest first. Requires the admin scope.
+func AuditExportHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+
+		filter, ok := parseAuditFilter(w, r)
+		if !ok {
+			return
+		}
+
+		enc := json.NewEncoder(w)
+		started := false
+		for {
+			events, err := is.AuditList(r.Context(), filter, _AUDIT_MAX_LIMIT)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to export audit events")
+				if !started {
+					w.WriteHeader(http.StatusInternalServerError)
+					enc.Encode(map[string]string{"error": "internal error"})
+					return
+				}
+				// abort the connection so a partial export is not mistaken for a complete one
+				panic(http.ErrAbortHandler)
+			}
+
+			if !started {
+				w.Header().Set("Content-Type", "application/x-ndjson")
+				w.Header().Set("Content-Disposition", `attachment; filename="audit.jsonl"`)
+				started = true
+			}
+			for _, event := range events {
+				if err := enc.Encode(event); err != nil {
+					return
+				}
+			}
+			if len(events) < _AUDIT_MAX_LIMIT {
+				return
+			}
+			last := events[len(events)-1]
+			filter.CursorCreatedAt, filter.CursorID = last.CreatedAt, last.ID
+		}
+	}
+}
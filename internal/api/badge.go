@@ -0,0 +1,81 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// GET /badge/views.svg?url=<url>&label=<text>&color=<color>&label_color=<color>&style=<style>
+func ViewsBadgeHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return badgeHandler(is, "views", func(r *http.Request, urlID int64) (int64, error) {
+		return is.ViewCountDisplay(r.Context(), urlID)
+	})
+}
+
+// GET /badge/likes.svg?url=<url>&label=<text>&color=<color>&label_color=<color>&style=<style>
+func LikesBadgeHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return badgeHandler(is, "likes", func(r *http.Request, urlID int64) (int64, error) {
+		return is.LikeCountDisplay(r.Context(), urlID)
+	})
+}
+
+// badgeHandler renders a count badge; unknown URLs and missing counts are shown as 0.
+// Colors are hex or shields.io color names and style is flat, flat-square or plastic.
+func badgeHandler(is types.InternalServiceProvider, defaultLabel string, lookup func(r *http.Request, urlID int64) (int64, error)) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		q := r.URL.Query()
+		opts := core.BadgeOptions{
+			Label:      q.Get("label"),
+			LabelColor: q.Get("label_color"),
+			Color:      q.Get("color"),
+			Style:      q.Get("style"),
+		}
+		if err := opts.Validate(defaultLabel); err != nil {
+			log.Debug().Err(err).Msg("invalid badge options")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid label, color or style"})
+			return
+		}
+
+		rawURL := q.Get("url")
+		if rawURL == "" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "url parameter is required"})
+			return
+		}
+
+		normalizedURL, err := is.NormalizeURL(rawURL)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid url"})
+			return
+		}
+
+		var count int64
+		urlRecord, err := is.UrlLookupByUrl(r.Context(), normalizedURL)
+		if err == nil {
+			count, err = lookup(r, urlRecord.ID)
+			if err != nil {
+				log.Debug().
+					Str("url", normalizedURL).
+					Int64("url_id", urlRecord.ID).
+					Err(err).
+					Msg("count not found")
+				count = 0
+			}
+		}
+
+		w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+		w.Header().Set("Cache-Control", "max-age=60, stale-while-revalidate=600")
+		w.WriteHeader(http.StatusOK)
+		w.Write(core.RenderBadge(core.FormatCount(count), opts))
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+)
+
+const _CLIENT_MAX_BODY_SIZE = 256 << 10
+
+var errUnsupportedContentType = errors.New("unsupported content type")
+
+// decodeClientRequest decodes the body of a client request into v. Besides JSON it accepts
+// the CORS-safelisted content types sent by navigator.sendBeacon without a preflight:
+// text/plain holding a JSON document, and application/x-www-form-urlencoded with either
+// a JSON "payload" field or one field per top-level string property of v.
+// It reports whether the request is a beacon, which should be answered with 204 No Content.
+func decodeClientRequest(w http.ResponseWriter, r *http.Request, v any) (bool, error) {
+	body := http.MaxBytesReader(w, r.Body, _CLIENT_MAX_BODY_SIZE)
+
+	mediaType := "application/json"
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		var err error
+		mediaType, _, err = mime.ParseMediaType(ct)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	switch mediaType {
+	case "application/json":
+		return false, json.NewDecoder(body).Decode(v)
+	case "text/plain":
+		return true, json.NewDecoder(body).Decode(v)
+	case "application/x-www-form-urlencoded":
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return true, err
+		}
+		form, err := url.ParseQuery(string(data))
+		if err != nil {
+			return true, err
+		}
+		if payload := form.Get("payload"); payload != "" {
+			return true, json.Unmarshal([]byte(payload), v)
+		}
+
+		fields := make(map[string]string, len(form))
+		for k := range form {
+			fields[k] = form.Get(k)
+		}
+		encoded, err := json.Marshal(fields)
+		if err != nil {
+			return true, err
+		}
+		return true, json.Unmarshal(encoded, v)
+	}
+
+	return false, errUnsupportedContentType
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// BlockRequest adds an entry to the blocklist
+type BlockRequest struct {
+	Kind      types.BlockKind `json:"kind"`
+	Value     string          `json:"value"`
+	Reason    string          `json:"reason"`
+	ExpiresIn string          `json:"expires_in"` // Go duration, e.g. "72h"; never expires if empty
+}
+
+// BlocklistResponse lists the blocklist
+type BlocklistResponse struct {
+	Entries []types.BlockEntry `json:"entries"`
+}
+
+// clientBlocked reports whether submissions of clientID through r are blocked, checking only
+// the IP address and User-Agent for anonymous requests with clientID 0. Blocked clients are
+// shadow banned: they get the usual responses, so they cannot tell that their submissions are
+// dropped.
+func clientBlocked(is types.InternalServiceProvider, r *http.Request, clientID int64) bool {
+	blocked := is.Blocked(types.BlockCheck{
+		ClientID:  clientID,
+		IP:        remoteIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if blocked {
+		log.Debug().Int64("client_id", clientID).Str("path", r.URL.Path).Msg("dropped submission of blocked client")
+	}
+	return blocked
+}
+
+// writeShadowBanned writes the success response of a client submission that was dropped
+func writeShadowBanned(w http.ResponseWriter, beacon bool) {
+	if beacon {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(_status_ok)
+}
+
+// reloadBlocklist applies a blocklist change to this node right away
+func reloadBlocklist(is types.InternalServiceProvider, r *http.Request) {
+	if err := is.BlocklistReload(r.Context()); err != nil {
+		log.Error().Err(err).Msg("failed to reload blocklist")
+	}
+}
+
+func blockTarget(e types.BlockEntry) string {
+	return e.Kind.String() + ":" + e.Value
+}
+
+// GET /admin/v1/blocklist
+//
+// Lists all blocklist entries, including expired ones not yet deleted. Requires write:moderation.
+func BlocklistListHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+
+		entries, err := is.BlocklistList(r.Context())
+		if err != nil {
+			log.Error().Err(err).Msg("failed to list blocklist")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "internal error"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(BlocklistResponse{Entries: entries})
+	}
+}
+
+// POST /admin/v1/blocklist
+//
+// Adds a BlockRequest to the blocklist, or updates the reason and expiry of an existing
+// entry with the same kind and value. Requires write:moderation.
+func BlocklistAddHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		defer r.Body.Close()
+
+		var req BlockRequest
+		err := json.NewDecoder(http.MaxBytesReader(w, r.Body, _CLIENT_MAX_BODY_SIZE)).Decode(&req)
+		if err != nil && !errors.Is(err, types.ErrInvalidBlockKind) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		value, err := core.NormalizeBlockValue(req.Kind, req.Value)
+		if errors.Is(err, types.ErrInvalidBlockKind) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "kind must be client, fingerprint, network or user_agent"})
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid " + req.Kind.String() + " value"})
+			return
+		}
+		if len(req.Reason) > 255 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "reason is too long"})
+			return
+		}
+
+		now := time.Now()
+		key, _ := AdminKey(r)
+		entry := types.BlockEntry{
+			Kind:      req.Kind,
+			Value:     value,
+			Reason:    req.Reason,
+			CreatedBy: key.ID,
+			CreatedAt: now.UnixNano(),
+		}
+		if req.ExpiresIn != "" {
+			d, err := time.ParseDuration(req.ExpiresIn)
+			if err != nil || d <= 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid expires_in"})
+				return
+			}
+			entry.ExpiresAt = now.Add(d).UnixNano()
+		}
+
+		entry.ID, err = is.GenerateID()
+		if err == nil {
+			entry, err = is.BlocklistAdd(r.Context(), entry)
+		}
+		if err != nil {
+			log.Error().Err(err).Str("kind", req.Kind.String()).Msg("failed to add blocklist entry")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "internal error"})
+			return
+		}
+
+		reloadBlocklist(is, r)
+		if !recordAudit(is, w, r, types.AuditBlocklistAdd, blockTarget(entry), nil, entry) {
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(entry)
+	}
+}
+
+// DELETE /admin/v1/blocklist/:id
+//
+// Removes a blocklist entry. Requires write:moderation.
+func BlocklistRemoveHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id, err := strconv.ParseInt(ps.ByName("id"), 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid id"})
+			return
+		}
+
+		entry, found, err := is.BlocklistRemove(r.Context(), id)
+		if err != nil {
+			log.Error().Err(err).Int64("id", id).Msg("failed to remove blocklist entry")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "internal error"})
+			return
+		}
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "blocklist entry not found"})
+			return
+		}
+
+		reloadBlocklist(is, r)
+		if !recordAudit(is, w, r, types.AuditBlocklistRemove, blockTarget(entry), entry, nil) {
+			return
+		}
+
+		json.NewEncoder(w).Encode(entry)
+	}
+}
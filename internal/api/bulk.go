@@ -6,7 +6,6 @@ import (
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/rs/zerolog/log"
-	"telemetry.gosuda.org/telemetry/internal/core"
 	"telemetry.gosuda.org/telemetry/internal/types"
 )
 
@@ -38,7 +37,7 @@ func BulkCountsHandler(is types.InternalServiceProvider) httprouter.Handle {
 		seenNormalized := make(map[string]struct{})
 
 		for _, originalUrl := range req.Urls {
-			n, err := core.NormalizeURL(originalUrl)
+			n, err := is.NormalizeURL(originalUrl)
 			if err != nil {
 				log.Debug().Str("url", originalUrl).Err(err).Msg("failed to normalize url")
 				w.WriteHeader(http.StatusBadRequest)
@@ -85,7 +84,7 @@ func BulkCountsHandler(is types.InternalServiceProvider) httprouter.Handle {
 		}
 		// Iterate through the original request URLs to maintain order
 		for _, originalUrl := range req.Urls {
-			normalizedUrl, err := core.NormalizeURL(originalUrl)
+			normalizedUrl, err := is.NormalizeURL(originalUrl)
 			if err != nil {
 				// This should ideally not happen again if it passed earlier validation
 				log.Debug().Str("url", originalUrl).Err(err).Msg("failed to re-normalize url for response building")
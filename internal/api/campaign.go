@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// landing pages listed per campaign
+const _CAMPAIGN_LANDING_PAGES = 10
+
+// GET /stats/campaigns?site=<host>&from=<time>&to=<time>&limit=<n>
+//
+// Returns the top limit campaigns with their top landing pages, and the top limit
+// landing pages over all campaigns.
+func StatsCampaignsHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "max-age=60, stale-while-revalidate=86400")
+
+		site, err := parseSiteParam(is, r)
+		if err != nil {
+			log.Debug().Err(err).Msg("failed to parse site")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid site"})
+			return
+		}
+
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			log.Debug().Err(err).Msg("failed to parse time range")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid time range"})
+			return
+		}
+
+		limit := int32(parseLimit(r, 100, 1000))
+		campaigns, err := is.CampaignStats(r.Context(), site, from, to, limit, _CAMPAIGN_LANDING_PAGES)
+		if err != nil {
+			log.Error().Err(err).Str("site", site).Msg("failed to query campaign stats")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		landingPages, err := is.CampaignLandingPages(r.Context(), site, from, to, limit)
+		if err != nil {
+			log.Error().Err(err).Str("site", site).Msg("failed to query campaign landing pages")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(types.CampaignStatsResponse{
+			Site:         site,
+			From:         from,
+			To:           to,
+			Campaigns:    campaigns,
+			LandingPages: landingPages,
+		})
+	}
+}
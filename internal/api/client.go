@@ -8,6 +8,7 @@ import (
 	"github.com/julienschmidt/httprouter"
 	"github.com/rs/zerolog/log"
 	"gosuda.org/randflake"
+	"telemetry.gosuda.org/telemetry/internal/core"
 	"telemetry.gosuda.org/telemetry/internal/types"
 )
 
@@ -183,6 +184,32 @@ func ClientCheckinHandler(is types.InternalServiceProvider) httprouter.Handle {
 			return
 		}
 
+		// The fingerprint is stored even for blocked clients so fingerprint blocks can find
+		// them, but their device does not count
+		blocked := is.Blocked(types.BlockCheck{
+			ClientID:    clientID,
+			Fingerprint: passport.Fingerprint,
+			IP:          remoteIP(r),
+			UserAgent:   r.UserAgent(),
+		})
+		if blocked {
+			log.Debug().Str("client_id", passport.ClientID).Msg("blocked client checked in")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+			return
+		}
+
+		// Parse the User-Agent and Client Hints into device dimensions. The checkin has
+		// already succeeded, so failing to store them is only logged.
+		device := core.ParseUserAgent(passport.UserAgent, passport.UserAgentData)
+		deviceID, err := is.GenerateID()
+		if err == nil {
+			err = is.ClientDeviceUpsert(context.Background(), deviceID, clientID, device)
+		}
+		if err != nil {
+			log.Error().Err(err).Str("client_id", passport.ClientID).Msg("failed to store client device")
+		}
+
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 	}
@@ -364,6 +364,51 @@ async function registerFingerprint(fingerprint) {
     }
 }
 
+const TELEMETRY_UTM_PARAMS = ["utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content"];
+
+/**
+ * Carries UTM campaign parameters of the current page over to a URL pointing to the same page,
+ * since data-url placeholders usually hold the canonical URL without a query string.
+ * @param {string} url - The URL to record a view for
+ * @returns {string} - The URL with the current page's UTM parameters appended
+ */
+function withCampaignParams(url) {
+    try {
+        const target = new URL(url, window.location.href);
+        const current = new URL(window.location.href);
+        if (target.host !== current.host || target.pathname.replace(/\/+$/, "") !== current.pathname.replace(/\/+$/, "")) {
+            return url;
+        }
+        for (const key of TELEMETRY_UTM_PARAMS) {
+            const value = current.searchParams.get(key);
+            if (value && !target.searchParams.has(key)) {
+                target.searchParams.set(key, value);
+            }
+        }
+        return target.toString();
+    } catch (e) {
+        return url;
+    }
+}
+
+/**
+ * Sends a request body with navigator.sendBeacon as text/plain, which needs no CORS preflight
+ * and survives page unload. The server answers beacons with 204 No Content.
+ * @param {string} path - API path, e.g. "/client/view"
+ * @param {string} body - JSON encoded request body
+ * @returns {boolean} - True if the browser queued the beacon
+ */
+function sendTelemetryBeacon(path, body) {
+    if (typeof navigator.sendBeacon !== "function") {
+        return false;
+    }
+    try {
+        return navigator.sendBeacon(TELEMETRY_BASEURL + path, body);
+    } catch (e) {
+        return false;
+    }
+}
+
 /**
  * Records a page view for the current URL
  * @param {string} url - The URL to record a view for (defaults to current page URL)
@@ -378,21 +423,35 @@ async function recordView(url = window.location.href) {
         return false;
     }
 
+    const body = JSON.stringify({
+        client_id: clientID,
+        client_token: clientToken,
+        url: withCampaignParams(url),
+        referrer: document.referrer,
+    });
+
+    // A fetch started while the page is being hidden may be cancelled; beacons are delivered anyway.
+    if (document.visibilityState === "hidden" && sendTelemetryBeacon("/client/view", body)) {
+        console.log("View beacon queued for:", url);
+        return true;
+    }
+
     try {
         const resp = await fetch(TELEMETRY_BASEURL + "/client/view", {
             method: "POST",
             headers: {
                 "Content-Type": "application/json",
             },
-            body: JSON.stringify({
-                client_id: clientID,
-                client_token: clientToken,
-                url: url,
-            }),
+            body: body,
+            keepalive: true,
         });
 
         if (resp.status === 200) {
             console.log("View recorded successfully for:", url);
+            const data = await resp.json().catch(() => ({}));
+            if (data.view_id) {
+                startEngagement(data.view_id);
+            }
             return true;
         } else {
             console.error("Failed to record view. Status:", resp.status);
@@ -601,6 +660,9 @@ async function initTelemetry() {
         try {
             hydrateSummaryCounts();
         } catch (e) { console.error("hydrateSummaryCounts failed:", e); }
+        try {
+            hydratePresence();
+        } catch (e) { console.error("hydratePresence failed:", e); }
     };
 
     // pre-hydrate
@@ -613,6 +675,9 @@ async function initTelemetry() {
         console.error("Telemetry initialization failed:", error);
     }
 
+    // send events queued while offline
+    flushEvents();
+
     // post-hydrate
     if (document.readyState === 'loading') {
         document.addEventListener('DOMContentLoaded', runHydrations, { once: true });
@@ -786,6 +851,481 @@ async function getBulkCounts(urls = []) {
     }
 }
 
+const TELEMETRY_EVENT_QUEUE_KEY = "telemetry_event_queue";
+const TELEMETRY_EVENT_QUEUE_MAX = 100;
+const TELEMETRY_EVENT_MAX_AGE = 24 * 60 * 60 * 1000; // the server rejects older events
+
+function loadEventQueue() {
+    try {
+        const queue = JSON.parse(localStorage.getItem(TELEMETRY_EVENT_QUEUE_KEY) || "[]");
+        const minTs = Date.now() - TELEMETRY_EVENT_MAX_AGE;
+        return Array.isArray(queue) ? queue.filter(e => e && e.ts > minTs) : [];
+    } catch (e) {
+        return [];
+    }
+}
+
+function saveEventQueue(queue) {
+    try {
+        localStorage.setItem(TELEMETRY_EVENT_QUEUE_KEY, JSON.stringify(queue.slice(-TELEMETRY_EVENT_QUEUE_MAX)));
+    } catch (e) {
+        console.warn("Failed to persist event queue:", e);
+    }
+}
+
+// Removes sent events from the queue, keeping events queued while the batch was in flight
+function dropQueuedEvents(batch) {
+    saveEventQueue(loadEventQueue().filter(e => !batch.some(b => b.ts === e.ts && b.type === e.type && b.url === e.url)));
+}
+
+/**
+ * Queues an event to be sent with the next batch. Events survive reloads and offline periods.
+ * @param {Object} event - { type: "view"|"like"|"custom", url, referrer?, name?, props? }
+ */
+function queueEvent(event) {
+    const queue = loadEventQueue();
+    queue.push(Object.assign({ url: window.location.href }, event, { ts: Date.now() }));
+    saveEventQueue(queue);
+}
+
+/**
+ * Sends queued events to POST /client/events. Events are kept while offline or on
+ * server errors and dropped once the server reported a result for them.
+ * @returns {Promise<Array|null>} - Per-event results, or null if nothing was sent
+ */
+async function flushEvents() {
+    let clientID = localStorage.getItem("telemetry_client_id");
+    let clientToken = localStorage.getItem("telemetry_client_token");
+
+    const queue = loadEventQueue();
+    if (!clientID || !clientToken || queue.length === 0 || navigator.onLine === false) {
+        return null;
+    }
+
+    const batch = queue.slice(0, TELEMETRY_EVENT_QUEUE_MAX);
+    try {
+        const resp = await fetch(TELEMETRY_BASEURL + "/client/events", {
+            method: "POST",
+            headers: {
+                "Content-Type": "application/json",
+            },
+            body: JSON.stringify({
+                client_id: clientID,
+                client_token: clientToken,
+                events: batch,
+            }),
+        });
+
+        if (resp.status === 200) {
+            const data = await resp.json();
+            dropQueuedEvents(batch);
+            return data.results;
+        } else if (resp.status === 400) {
+            // Malformed batch; drop it rather than retrying forever
+            dropQueuedEvents(batch);
+        }
+        console.error("Failed to send events. Status:", resp.status);
+        return null;
+    } catch (error) {
+        console.error("Error sending events:", error);
+        return null;
+    }
+}
+
+/**
+ * Records a custom event, queueing it if it cannot be sent right away.
+ * @param {string} name - Event name (letters, digits, _ . : -)
+ * @param {Object} props - Optional properties, up to 10 string values
+ * @param {string} url - The URL the event happened on (defaults to current page URL)
+ */
+async function trackEvent(name, props = {}, url = window.location.href) {
+    queueEvent({ type: "custom", url: url, name: name, props: props });
+    return await flushEvents();
+}
+
+/**
+ * Sends all queued events with a beacon. Used on pagehide, when fetch requests may be cancelled.
+ */
+function flushEventsWithBeacon() {
+    let clientID = localStorage.getItem("telemetry_client_id");
+    let clientToken = localStorage.getItem("telemetry_client_token");
+
+    const batch = loadEventQueue().slice(0, TELEMETRY_EVENT_QUEUE_MAX);
+    if (!clientID || !clientToken || batch.length === 0) {
+        return;
+    }
+
+    const body = JSON.stringify({
+        client_id: clientID,
+        client_token: clientToken,
+        events: batch,
+    });
+    if (sendTelemetryBeacon("/client/events", body)) {
+        dropQueuedEvents(batch);
+    }
+}
+
+const TELEMETRY_ENGAGEMENT_HEARTBEAT = 15 * 1000;
+const TELEMETRY_ENGAGEMENT_IDLE = 30 * 1000; // time without input after which the reader counts as away
+
+// Engagement of the first view recorded on this page
+let telemetryEngagement = null;
+
+// Returns how far the page has been scrolled, in percent of the scrollable height
+function currentScrollDepth() {
+    const doc = document.documentElement;
+    const scrollable = doc.scrollHeight - window.innerHeight;
+    if (scrollable <= 0) {
+        return 100;
+    }
+    return Math.min(100, Math.max(0, Math.round(window.scrollY / scrollable * 100)));
+}
+
+// Adds the time since the last tick to the active time if the page is visible and the reader is not idle
+function tickEngagement() {
+    const e = telemetryEngagement;
+    const now = Date.now();
+    if (document.visibilityState === "visible" && now - e.lastInput < TELEMETRY_ENGAGEMENT_IDLE) {
+        e.activeMs += now - e.lastTick;
+    }
+    e.lastTick = now;
+    e.maxScroll = Math.max(e.maxScroll, currentScrollDepth());
+}
+
+function engagementBody() {
+    return JSON.stringify({
+        client_id: localStorage.getItem("telemetry_client_id"),
+        client_token: localStorage.getItem("telemetry_client_token"),
+        view_id: telemetryEngagement.viewID,
+        active_ms: Math.round(telemetryEngagement.activeMs),
+        max_scroll: telemetryEngagement.maxScroll,
+    });
+}
+
+/**
+ * Sends the cumulative engagement of the tracked view to POST /client/engagement.
+ * @param {boolean} beacon - Use a beacon, for page unload
+ */
+async function reportEngagement(beacon = false) {
+    if (!telemetryEngagement) {
+        return;
+    }
+    tickEngagement();
+    if (telemetryEngagement.activeMs === telemetryEngagement.sentMs && telemetryEngagement.maxScroll === telemetryEngagement.sentScroll) {
+        return;
+    }
+    telemetryEngagement.sentMs = telemetryEngagement.activeMs;
+    telemetryEngagement.sentScroll = telemetryEngagement.maxScroll;
+
+    const body = engagementBody();
+    if (beacon && sendTelemetryBeacon("/client/engagement", body)) {
+        return;
+    }
+    try {
+        await fetch(TELEMETRY_BASEURL + "/client/engagement", {
+            method: "POST",
+            headers: {
+                "Content-Type": "application/json",
+            },
+            body: body,
+            keepalive: true,
+        });
+    } catch (error) {
+        console.error("Error reporting engagement:", error);
+    }
+}
+
+/**
+ * Starts tracking active reading time and scroll depth for a recorded view.
+ * Only the first view recorded on a page is tracked.
+ * @param {string} viewID - view_id returned by /client/view
+ */
+function startEngagement(viewID) {
+    if (telemetryEngagement) {
+        return;
+    }
+    const now = Date.now();
+    telemetryEngagement = {
+        viewID: viewID,
+        activeMs: 0,
+        maxScroll: currentScrollDepth(),
+        lastTick: now,
+        lastInput: now,
+        sentMs: -1,
+        sentScroll: -1,
+    };
+
+    const onInput = () => {
+        tickEngagement();
+        telemetryEngagement.lastInput = Date.now();
+    };
+    for (const type of ["scroll", "keydown", "mousemove", "pointerdown", "touchstart"]) {
+        window.addEventListener(type, onInput, { passive: true });
+    }
+    document.addEventListener("visibilitychange", () => {
+        if (document.visibilityState === "hidden") {
+            reportEngagement(true);
+        } else {
+            // time spent hidden does not count
+            telemetryEngagement.lastTick = Date.now();
+            telemetryEngagement.lastInput = Date.now();
+        }
+    });
+    window.addEventListener("pagehide", () => { reportEngagement(true); });
+    setInterval(() => {
+        if (document.visibilityState === "visible") {
+            reportEngagement();
+        }
+    }, TELEMETRY_ENGAGEMENT_HEARTBEAT);
+}
+
+// Presence: a WebSocket per page that counts the reader as present while the page is visible
+const TELEMETRY_PRESENCE_HEARTBEAT = 20 * 1000; // the server drops readers silent for a minute
+const TELEMETRY_PRESENCE_MAX_BACKOFF = 60 * 1000;
+let telemetryPresence = null;
+
+function connectPresence(url = window.location.href, onMessage = () => { }) {
+    if (telemetryPresence || typeof WebSocket === "undefined") {
+        return;
+    }
+    telemetryPresence = { socket: null, backoff: 1000, paused: false };
+
+    const heartbeat = () => {
+        const socket = telemetryPresence.socket;
+        if (socket && socket.readyState === WebSocket.OPEN && document.visibilityState === "visible") {
+            socket.send(JSON.stringify({ type: "heartbeat" }));
+        }
+    };
+
+    const connect = () => {
+        const wsURL = TELEMETRY_BASEURL.replace(/^http/, "ws") + "/live/ws?url=" + encodeURIComponent(url);
+        const socket = new WebSocket(wsURL);
+        telemetryPresence.socket = socket;
+        socket.onopen = () => {
+            telemetryPresence.backoff = 1000;
+            heartbeat();
+        };
+        socket.onmessage = (ev) => {
+            try {
+                onMessage(JSON.parse(ev.data));
+            } catch (e) {
+                console.error("presence message failed:", e);
+            }
+        };
+        socket.onclose = (ev) => {
+            // hidden pages are not present; reconnect once the page is visible again
+            if (document.visibilityState === "hidden") {
+                telemetryPresence.paused = true;
+                return;
+            }
+            // 1012: the server is restarting, reconnect to another node soon
+            const delay = ev.code === 1012 ? 1000 + Math.random() * 4000 : telemetryPresence.backoff;
+            telemetryPresence.backoff = Math.min(telemetryPresence.backoff * 2, TELEMETRY_PRESENCE_MAX_BACKOFF);
+            setTimeout(connect, delay);
+        };
+    };
+
+    connect();
+    setInterval(heartbeat, TELEMETRY_PRESENCE_HEARTBEAT);
+    document.addEventListener("visibilitychange", () => {
+        if (document.visibilityState === "visible" && telemetryPresence.paused) {
+            telemetryPresence.paused = false;
+            connect();
+        } else {
+            heartbeat();
+        }
+    });
+}
+
+// Hydrate [data-presence-count] placeholders with the number of readers on this page,
+// and keep like counts of this page current.
+function hydratePresence() {
+    if (isCrawler()) return;
+
+    const presenceEls = document.querySelectorAll('[data-presence-count]');
+    if (presenceEls.length === 0) return;
+
+    const url = window.location.href;
+    connectPresence(url, (m) => {
+        if (m.type === "presence") {
+            for (const el of presenceEls) {
+                el.textContent = `reading now ${m.present}`;
+            }
+        }
+        if (m.type === "presence" || m.type === "like") {
+            for (const btn of document.querySelectorAll('[data-like-button]')) {
+                const span = btn.querySelector('[data-like-count]');
+                if (span && btn.getAttribute('data-url') === url) {
+                    span.textContent = `likes ${m.likes}`;
+                }
+            }
+        }
+    });
+}
+
+// Core Web Vitals of this page load, collected with PerformanceObserver
+const telemetryVitals = {};
+let telemetryVitalsSent = false;
+
+function observeVitals(type, callback) {
+    try {
+        new PerformanceObserver((list) => list.getEntries().forEach(callback)).observe({ type: type, buffered: true });
+    } catch (e) {
+        // entry type not supported by this browser
+    }
+}
+
+/**
+ * Starts collecting LCP, INP, CLS, FCP and TTFB of the current page load.
+ * INP is approximated by the longest interaction; CLS uses 5 second session windows.
+ */
+function collectVitals() {
+    if (typeof PerformanceObserver !== "function") {
+        return;
+    }
+
+    observeVitals("largest-contentful-paint", (e) => { telemetryVitals.LCP = e.startTime; });
+    observeVitals("paint", (e) => {
+        if (e.name === "first-contentful-paint") {
+            telemetryVitals.FCP = e.startTime;
+        }
+    });
+    observeVitals("navigation", (e) => {
+        if (e.responseStart > 0) {
+            telemetryVitals.TTFB = e.responseStart;
+        }
+    });
+
+    try {
+        new PerformanceObserver((list) => {
+            for (const e of list.getEntries()) {
+                if (e.interactionId) {
+                    telemetryVitals.INP = Math.max(telemetryVitals.INP || 0, e.duration);
+                }
+            }
+        }).observe({ type: "event", buffered: true, durationThreshold: 40 });
+    } catch (e) {
+        // event timing not supported by this browser
+    }
+
+    let session = 0, sessionStart = 0, sessionLast = 0;
+    observeVitals("layout-shift", (e) => {
+        if (e.hadRecentInput) {
+            return;
+        }
+        if (session > 0 && e.startTime - sessionLast < 1000 && e.startTime - sessionStart < 5000) {
+            session += e.value;
+        } else {
+            session = e.value;
+            sessionStart = e.startTime;
+        }
+        sessionLast = e.startTime;
+        telemetryVitals.CLS = Math.max(telemetryVitals.CLS || 0, session);
+    });
+}
+
+/**
+ * Sends the collected web vitals to POST /client/vitals with a beacon. Only the first call sends.
+ */
+function sendVitals() {
+    let clientID = localStorage.getItem("telemetry_client_id");
+    let clientToken = localStorage.getItem("telemetry_client_token");
+
+    if (telemetryVitalsSent || !clientID || !clientToken || Object.keys(telemetryVitals).length === 0) {
+        return;
+    }
+    telemetryVitalsSent = true;
+
+    const body = JSON.stringify({
+        client_id: clientID,
+        client_token: clientToken,
+        url: window.location.href,
+        metrics: telemetryVitals,
+    });
+    if (!sendTelemetryBeacon("/client/vitals", body)) {
+        fetch(TELEMETRY_BASEURL + "/client/vitals", {
+            method: "POST",
+            headers: {
+                "Content-Type": "application/json",
+            },
+            body: body,
+            keepalive: true,
+        }).catch((error) => console.error("Error sending web vitals:", error));
+    }
+}
+
+const TELEMETRY_ERROR_MAX_REPORTS = 5; // per page load; the server also rate limits per client
+const telemetryReportedErrors = new Set();
+
+/**
+ * Reports a JavaScript error to POST /client/error. Repeated errors are sent once per page load.
+ * @param {string} message - Error message
+ * @param {string} stack - Error stack, if any
+ * @param {string} source - URL of the script that threw
+ * @param {number} line - Line number
+ * @param {number} column - Column number
+ */
+function reportError(message, stack = "", source = "", line = 0, column = 0) {
+    let clientID = localStorage.getItem("telemetry_client_id");
+    let clientToken = localStorage.getItem("telemetry_client_token");
+
+    const key = message + "\n" + source + ":" + line + ":" + column;
+    if (!clientID || !clientToken || !message || telemetryReportedErrors.has(key) ||
+        telemetryReportedErrors.size >= TELEMETRY_ERROR_MAX_REPORTS) {
+        return;
+    }
+    telemetryReportedErrors.add(key);
+
+    const body = JSON.stringify({
+        client_id: clientID,
+        client_token: clientToken,
+        url: window.location.href,
+        message: String(message),
+        stack: String(stack || ""),
+        source: String(source || ""),
+        line: line || 0,
+        column: column || 0,
+        user_agent: navigator.userAgent,
+    });
+    if (!sendTelemetryBeacon("/client/error", body)) {
+        fetch(TELEMETRY_BASEURL + "/client/error", {
+            method: "POST",
+            headers: {
+                "Content-Type": "application/json",
+            },
+            body: body,
+            keepalive: true,
+        }).catch(() => { /* never report errors of the error reporter */ });
+    }
+}
+
+window.addEventListener("error", (ev) => {
+    // resource load errors have no message
+    if (!ev.message) {
+        return;
+    }
+    reportError(ev.message, ev.error && ev.error.stack, ev.filename, ev.lineno, ev.colno);
+});
+window.addEventListener("unhandledrejection", (ev) => {
+    const reason = ev.reason;
+    if (reason instanceof Error) {
+        reportError("Unhandled rejection: " + reason.message, reason.stack);
+    } else {
+        reportError("Unhandled rejection: " + String(reason));
+    }
+});
+
+collectVitals();
+document.addEventListener("visibilitychange", () => {
+    if (document.visibilityState === "hidden") {
+        sendVitals();
+    }
+});
+window.addEventListener("pagehide", sendVitals);
+
+window.addEventListener("online", () => { flushEvents(); });
+window.addEventListener("pagehide", flushEventsWithBeacon);
+
 // Make functions available globally for manual use
 window.recordView = recordView;
 window.getViewCount = getViewCount;
@@ -794,3 +1334,8 @@ window.recordLike = recordLike;
 window.getLikeCount = getLikeCount;
 window.getBulkCounts = getBulkCounts;
 window.hydrateSummaryCounts = hydrateSummaryCounts;
+window.queueEvent = queueEvent;
+window.flushEvents = flushEvents;
+window.trackEvent = trackEvent;
+window.connectPresence = connectPresence;
+window.reportError = reportError;
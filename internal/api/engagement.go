@@ -0,0 +1,148 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"gosuda.org/randflake"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// reports beyond this much reading time per view are clamped
+const _ENGAGEMENT_MAX_ACTIVE_MS = 4 * 60 * 60 * 1000
+
+// EngagementRequest is a heartbeat or unload report of a page view
+type EngagementRequest struct {
+	ClientID    string `json:"client_id"`
+	ClientToken string `json:"client_token"`
+	ViewID      string `json:"view_id"`    // view_id returned by /client/view
+	ActiveMs    int64  `json:"active_ms"`  // total active reading time so far in milliseconds
+	MaxScroll   int32  `json:"max_scroll"` // maximum scroll depth so far in percent (0-100)
+}
+
+// POST /client/engagement
+// Reports are cumulative; beacon bodies are accepted like for /client/view and answered with 204 No Content.
+func ClientEngagementHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		defer r.Body.Close()
+
+		engagementRequest := EngagementRequest{}
+		beacon, err := decodeClientRequest(w, r, &engagementRequest)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to decode engagement request")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		log.Debug().
+			Str("client_id", engagementRequest.ClientID).
+			Str("view_id", engagementRequest.ViewID).
+			Int64("active_ms", engagementRequest.ActiveMs).
+			Int32("max_scroll", engagementRequest.MaxScroll).
+			Msg("Engagement Request Received")
+
+		viewID, err := randflake.DecodeString(engagementRequest.ViewID)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid view_id"})
+			return
+		}
+
+		// Verify client credentials
+		clientID, err := randflake.DecodeString(engagementRequest.ClientID)
+		if err != nil {
+			log.Debug().
+				Str("client_id", engagementRequest.ClientID).
+				Err(err).
+				Msg("Failed to decode client ID")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid client_id"})
+			return
+		}
+
+		ok, err := is.ClientVerifyToken(r.Context(), clientID, engagementRequest.ClientToken)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to verify client token")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if !ok {
+			log.Debug().
+				Str("client_id", engagementRequest.ClientID).
+				Msg("Client token verification failed")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"status": "unauthorized"})
+			return
+		}
+
+		activeMs := min(max(engagementRequest.ActiveMs, 0), _ENGAGEMENT_MAX_ACTIVE_MS)
+		maxScroll := min(max(engagementRequest.MaxScroll, 0), 100)
+
+		if clientBlocked(is, r, clientID) {
+			writeShadowBanned(w, beacon)
+			return
+		}
+
+		found, err := is.EngagementRecord(r.Context(), viewID, clientID, activeMs, maxScroll)
+		if err != nil {
+			log.Error().Err(err).Int64("view_id", viewID).Msg("failed to record engagement")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "view not found"})
+			return
+		}
+
+		if beacon {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	}
+}
+
+// GET /stats/engagement?url=<url>&from=<time>&to=<time>
+func StatsEngagementHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "max-age=60, stale-while-revalidate=86400")
+
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			log.Debug().Err(err).Msg("failed to parse time range")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid time range"})
+			return
+		}
+
+		urlRecord, ok := lookupStatsURL(is, w, r)
+		if !ok {
+			return
+		}
+
+		stats, err := is.EngagementStatsByUrl(r.Context(), urlRecord.ID, from, to)
+		if err != nil {
+			log.Error().Err(err).Int64("url_id", urlRecord.ID).Msg("failed to query engagement stats")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(types.EngagementStatsResponse{
+			URL:             urlRecord.Url,
+			From:            from,
+			To:              to,
+			EngagementStats: stats,
+		})
+	}
+}
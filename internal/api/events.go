@@ -0,0 +1,372 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"gosuda.org/randflake"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+const _EVENTS_MAX_BATCH = 100
+
+// EventRequest is a single event of a batch
+type EventRequest struct {
+	Type       string          `json:"type"`     // "view", "like" or "custom"
+	URL        string          `json:"url"`      // URL the event happened on
+	Timestamp  int64           `json:"ts"`       // client timestamp in unix milliseconds, 0 for now
+	Referrer   string          `json:"referrer"` // view events (optional)
+	Name       string          `json:"name"`     // custom events
+	Properties json.RawMessage `json:"props"`    // custom events, JSON object of strings (optional)
+}
+
+// EventsRequest is a batch of events queued by a client
+type EventsRequest struct {
+	ClientID    string         `json:"client_id"`
+	ClientToken string         `json:"client_token"`
+	Events      []EventRequest `json:"events"`
+}
+
+// EventsResponse reports the outcome of every event of a batch in request order
+type EventsResponse struct {
+	Status  string              `json:"status"`
+	Results []types.EventResult `json:"results"`
+}
+
+// POST /client/events
+// Beacon bodies (text/plain or form) are accepted like for /client/view and answered with 204 No Content.
+func ClientEventsHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		defer r.Body.Close()
+
+		eventsRequest := EventsRequest{}
+		beacon, err := decodeClientRequest(w, r, &eventsRequest)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to decode events request")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		log.Debug().
+			Str("client_id", eventsRequest.ClientID).
+			Int("events", len(eventsRequest.Events)).
+			Msg("Events Request Received")
+
+		if len(eventsRequest.Events) == 0 || len(eventsRequest.Events) > _EVENTS_MAX_BATCH {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "events must contain 1 to 100 events"})
+			return
+		}
+
+		// Verify client credentials
+		clientID, err := randflake.DecodeString(eventsRequest.ClientID)
+		if err != nil {
+			log.Debug().
+				Str("client_id", eventsRequest.ClientID).
+				Err(err).
+				Msg("Failed to decode client ID")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid client_id"})
+			return
+		}
+
+		ok, err := is.ClientVerifyToken(r.Context(), clientID, eventsRequest.ClientToken)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to verify client token")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if !ok {
+			log.Debug().
+				Str("client_id", eventsRequest.ClientID).
+				Msg("Client token verification failed")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"status": "unauthorized"})
+			return
+		}
+
+		// Validate every event; only valid events are written. Valid events of blocked
+		// clients are reported as recorded.
+		blocked := clientBlocked(is, r, clientID)
+		now := time.Now()
+		results := make([]types.EventResult, len(eventsRequest.Events))
+		events := make([]types.ClientEvent, 0, len(eventsRequest.Events))
+		indexes := make([]int, 0, len(eventsRequest.Events))
+		for i, e := range eventsRequest.Events {
+			results[i] = types.EventResult{Index: i, Status: "error"}
+
+			event, err := parseEvent(is, e, clientID, now)
+			if err != nil {
+				results[i].Error = err.Error()
+				continue
+			}
+
+			if blocked {
+				results[i].Status = "ok"
+				continue
+			}
+
+			// Generate IDs for the event, its URL and count (in case we need to create them)
+			for _, id := range []*int64{&event.ID, &event.UrlID, &event.CountID} {
+				*id, err = is.GenerateID()
+				if err != nil {
+					log.Error().Err(err).Msg("failed to generate event ID")
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+			}
+
+			events = append(events, event)
+			indexes = append(indexes, i)
+		}
+
+		if len(events) > 0 {
+			inserted, err := is.EventBatchInsert(r.Context(), events, core.EventMaxNamesPerSite, core.EventMaxNamesPerClient)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to insert events")
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			for j, i := range indexes {
+				switch inserted[j].Status {
+				case types.EventInserted:
+					results[i].Status = "ok"
+					if events[j].Type != types.EventTypeCustom {
+						is.LiveNotify(inserted[j].URL)
+					}
+				case types.EventDuplicate:
+					results[i].Status = "duplicate"
+				case types.EventNameRejected:
+					results[i].Error = errTooManyEventNames.Error()
+				}
+			}
+		}
+
+		if beacon {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(EventsResponse{Status: "ok", Results: results})
+	}
+}
+
+// validation errors reported to the client in an EventResult
+var (
+	errInvalidEventURL        = errors.New("invalid url")
+	errInvalidEventTime       = errors.New("invalid timestamp")
+	errInvalidEventName       = errors.New("invalid event name")
+	errInvalidEventProperties = errors.New("invalid event properties")
+	errUnknownEventType       = errors.New("unknown event type")
+	errTooManyEventNames      = errors.New("too many distinct event names for this site or client")
+)
+
+// parseEvent validates an event of a batch and converts it into a ClientEvent without IDs
+func parseEvent(is types.InternalServiceProvider, e EventRequest, clientID int64, now time.Time) (types.ClientEvent, error) {
+	event := types.ClientEvent{
+		Type:     e.Type,
+		ClientID: clientID,
+	}
+
+	normalizedURL, err := is.NormalizeURL(e.URL)
+	if err != nil {
+		return event, errInvalidEventURL
+	}
+	event.URL = normalizedURL
+
+	event.CreatedAt, err = core.EventTime(e.Timestamp, now)
+	if err != nil {
+		return event, errInvalidEventTime
+	}
+
+	switch e.Type {
+	case types.EventTypeView:
+		event.Referrer = core.ParseReferrer(e.Referrer, e.URL)
+		event.Campaign = core.ExtractCampaign(e.URL)
+	case types.EventTypeLike:
+	case types.EventTypeCustom:
+		if err := core.ValidateEventName(e.Name); err != nil {
+			return event, errInvalidEventName
+		}
+		event.Name = e.Name
+		event.Site = core.URLSite(normalizedURL)
+		event.Properties, err = core.EventProperties(e.Properties)
+		if err != nil {
+			return event, errInvalidEventProperties
+		}
+	default:
+		return event, errUnknownEventType
+	}
+
+	return event, nil
+}
+
+// CustomEventRequest is a single custom event sent outside of a batch
+type CustomEventRequest struct {
+	ClientID    string          `json:"client_id"`
+	ClientToken string          `json:"client_token"`
+	URL         string          `json:"url"`   // URL the event happened on
+	Name        string          `json:"name"`  // e.g. "newsletter_signup"
+	Properties  json.RawMessage `json:"props"` // JSON object of strings (optional)
+}
+
+// POST /client/event
+// Beacon bodies are accepted like for /client/view and answered with 204 No Content.
+func ClientEventHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		defer r.Body.Close()
+
+		eventRequest := CustomEventRequest{}
+		beacon, err := decodeClientRequest(w, r, &eventRequest)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to decode event request")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		log.Debug().
+			Str("client_id", eventRequest.ClientID).
+			Str("url", eventRequest.URL).
+			Str("name", eventRequest.Name).
+			Msg("Event Request Received")
+
+		// Verify client credentials
+		clientID, err := randflake.DecodeString(eventRequest.ClientID)
+		if err != nil {
+			log.Debug().
+				Str("client_id", eventRequest.ClientID).
+				Err(err).
+				Msg("Failed to decode client ID")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid client_id"})
+			return
+		}
+
+		ok, err := is.ClientVerifyToken(r.Context(), clientID, eventRequest.ClientToken)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to verify client token")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if !ok {
+			log.Debug().
+				Str("client_id", eventRequest.ClientID).
+				Msg("Client token verification failed")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"status": "unauthorized"})
+			return
+		}
+
+		event, err := parseEvent(is, EventRequest{
+			Type:       types.EventTypeCustom,
+			URL:        eventRequest.URL,
+			Name:       eventRequest.Name,
+			Properties: eventRequest.Properties,
+		}, clientID, time.Now())
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+
+		if clientBlocked(is, r, clientID) {
+			writeShadowBanned(w, beacon)
+			return
+		}
+
+		// Generate IDs for the event and its URL (in case we need to create one)
+		for _, id := range []*int64{&event.ID, &event.UrlID} {
+			*id, err = is.GenerateID()
+			if err != nil {
+				log.Error().Err(err).Msg("failed to generate event ID")
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		}
+
+		inserted, err := is.EventBatchInsert(r.Context(), []types.ClientEvent{event}, core.EventMaxNamesPerSite, core.EventMaxNamesPerClient)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to insert event")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if inserted[0].Status == types.EventNameRejected {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]string{"error": errTooManyEventNames.Error()})
+			return
+		}
+
+		if beacon {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	}
+}
+
+// GET /stats/events?url=<url>&from=<time>&to=<time>&limit=<n>
+// GET /stats/events?site=<host>&from=<time>&to=<time>&limit=<n>
+// Counts custom events per name of a single URL, or of all URLs of a site if url is not given.
+func StatsEventsHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "max-age=60, stale-while-revalidate=86400")
+
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			log.Debug().Err(err).Msg("failed to parse time range")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid time range"})
+			return
+		}
+		limit := int32(parseLimit(r, 50, 500))
+
+		resp := types.EventStatsResponse{From: from, To: to}
+		if r.URL.Query().Get("url") != "" {
+			urlRecord, ok := lookupStatsURL(is, w, r)
+			if !ok {
+				return
+			}
+			resp.URL = urlRecord.Url
+
+			resp.Results, err = is.EventStatsByUrl(r.Context(), urlRecord.ID, from, to, limit)
+			if err != nil {
+				log.Error().Err(err).Int64("url_id", urlRecord.ID).Msg("failed to query event stats")
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		} else {
+			resp.Site, err = parseSiteParam(is, r)
+			if err != nil {
+				log.Debug().Err(err).Msg("failed to parse site")
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid site"})
+				return
+			}
+
+			resp.Results, err = is.EventStatsBySite(r.Context(), resp.Site, from, to, limit)
+			if err != nil {
+				log.Error().Err(err).Str("site", resp.Site).Msg("failed to query event stats")
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
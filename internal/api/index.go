@@ -1,7 +1,11 @@
 package api
 
 import (
+	"bytes"
+	"fmt"
+	"html"
 	"net/http"
+	"strings"
 
 	"github.com/julienschmidt/httprouter"
 	"telemetry.gosuda.org/telemetry/internal/types"
@@ -20,26 +24,56 @@ var _index_html = []byte(
 	<p>Public APIs:</p>
 	<ul>
 		<li>GET <a href="/healthz">/healthz</a> - Check the health of the service</li>
-		<li>GET <a href="/idz">/idz</a> - Generate a new randflake ID</li>
 		<li>POST <code>/client/like</code> - Submit a like (JSON: client_id, client_token, url)</li>
 		<li>GET <code>/like/count?url=<url></code> - Get like count for a normalized URL (host + pathname)</li>
-		<li>POST <code>/client/view</code> - Submit a view (JSON: client_id, client_token, url)</li>
+		<li>POST <code>/client/view</code> - Submit a view (JSON: client_id, client_token, url, referrer)</li>
+		<li>POST <code>/client/event</code> - Submit a custom event (JSON: client_id, client_token, url, name, props: up to 10 string properties); a site can use up to 200 distinct event names</li>
+		<li>POST <code>/client/events</code> - Submit up to 100 queued events in one transaction (JSON: client_id, client_token, events: [{type: view|like|custom, url, ts, referrer, name, props}]); the response has a result per event</li>
+		<li>POST <code>/client/engagement</code> - Report cumulative reading time and scroll depth of a view (JSON: client_id, client_token, view_id from /client/view, active_ms, max_scroll in percent)</li>
+		<li>POST <code>/client/vitals</code> - Submit the Core Web Vitals of a page load (JSON: client_id, client_token, url, metrics: {LCP, INP, CLS, FCP, TTFB}, times in milliseconds)</li>
+		<li>POST <code>/client/error</code> - Report a JavaScript error (JSON: client_id, client_token, url, message, stack, source, line, column, user_agent); limited to 10 reports per client and minute by default</li>
 		<li>GET <code>/view/count?url=<url></code> - Get view count for a normalized URL (host + pathname)</li>
+		<li>GET <code>/p.gif?url=<url></code> - Tracking pixel for readers without JavaScript (e.g. <code>&lt;img src="/p.gif?url=..."&gt;</code> in feeds); records an anonymous view once per IP address, URL and day and ignores crawlers</li>
+		<li>GET <code>/badge/views.svg?url=<url></code>, <code>/badge/likes.svg?url=<url></code> - SVG count badges (optional: label, color, label_color as hex or name, style=flat|flat-square|plastic)</li>
+		<li>GET <code>/badge/sparkline.svg?url=<url></code> - SVG line chart of daily views (optional: days up to 365, default 30; width, height, stroke_width in px; color, fill as hex, name or none)</li>
 		<li>POST <code>/counts/bulk</code> - Bulk lookup counts for multiple URLs (JSON body: { "urls": ["https://...","..."] })</li>
+		<li>GET <code>/stats/devices?url=<url></code> - Views of a URL broken down by browser, OS, device class and mobile flag</li>
+		<li>GET <code>/stats/referrers?url=<url>&from=<time>&to=<time></code> - Ranked traffic sources of a URL (times as RFC 3339, YYYY-MM-DD or unix seconds)</li>
+		<li>GET <code>/top?site=<host>&metric=views|likes|trending&window=24h|7d|30d|all&limit=<n></code> - Most viewed, liked or trending URLs, rebuilt every 10 minutes</li>
+		<li>GET <code>/related?url=<url>&limit=<n></code> - Pages of the same site most often read by readers of a URL, rebuilt hourly</li>
+		<li>GET <code>/live?url=<url></code> - Server-Sent Events stream of <code>update</code> events with the live reader count and the view and like counts of a URL</li>
+		<li>GET <code>/live/ws?url=<url></code> - WebSocket presence channel: send <code>{"type":"heartbeat"}</code> at least every minute to count as present, receive <code>presence</code> and <code>like</code> messages</li>
+		<li>GET <code>/stats/campaigns?site=<host>&from=<time>&to=<time>&limit=<n></code> - Top UTM campaigns with their top landing pages, and top landing pages</li>
+		<li>GET <code>/stats/events?url=<url>&from=<time>&to=<time></code> - Custom event counts per event name of a URL, or of a whole site with <code>site=<host></code> instead of url</li>
+		<li>GET <code>/stats/vitals?url=<url>&from=<time>&to=<time></code> - p50, p75 and p95 of each web vital of a URL, overall and per device class</li>
+		<li>GET <code>/stats/engagement?url=<url>&from=<time>&to=<time></code> - Median reading time and scroll depth distribution of the views of a URL</li>
 	</ul>
+	<p>Browser SDK (cached as immutable; pin it with the integrity attribute):</p>
+	<ul>
+{{SDK}}	</ul>
 	<p>Notes:</p>
 	<ul>
-		<li>URLs are normalized to host + pathname before storage and queries.</li>
+		<li>URLs are normalized to host + pathname before storage and queries, following per-site canonicalization rules (host case, IDNA, www folding, index.html, trailing slash, allowed query parameters, path case). UTM parameters (utm_source, utm_medium, utm_campaign, utm_term, utm_content) are extracted from viewed URLs before normalization.</li>
 		<li>CORS: all origins are allowed.</li>
+		<li>Admin routes under <code>/admin/v1</code> require an API key in an <code>Authorization: Bearer</code> header; see the README.</li>
+		<li>/client/view, /client/event, /client/events, /client/engagement, /client/vitals and /client/error also accept <code>navigator.sendBeacon</code> bodies (text/plain JSON, or form encoded with a JSON <code>payload</code> field or plain fields) without a CORS preflight and answer them with 204 No Content.</li>
 	</ul>
 </body>
 </html>`,
 )
 
-func IndexHandler(is types.InternalServiceProvider) httprouter.Handle {
+// IndexHandler serves the index page, listing the SDK scripts prepared by sdkAssets
+func IndexHandler(is types.InternalServiceProvider, assets []sdkAsset) httprouter.Handle {
+	var sdk strings.Builder
+	for _, asset := range assets {
+		fmt.Fprintf(&sdk, "\t\t<li><code>&lt;script src=\"%s%s\" integrity=\"%s\" crossorigin=\"anonymous\"&gt;&lt;/script&gt;</code></li>\n",
+			html.EscapeString(is.PublicBaseURL()), sdkPath(asset.name), asset.integrity)
+	}
+	page := bytes.Replace(_index_html, []byte("{{SDK}}"), []byte(sdk.String()), 1)
+
 	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 		w.Header().Set("Content-Type", "text/html")
 		w.WriteHeader(http.StatusOK)
-		w.Write(_index_html)
+		w.Write(page)
 	}
 }
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"gosuda.org/randflake"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// ErrorRequest is a client-side JavaScript error
+type ErrorRequest struct {
+	ClientID    string `json:"client_id"`
+	ClientToken string `json:"client_token"`
+	URL         string `json:"url"`        // page the error happened on
+	Message     string `json:"message"`    // error message
+	Stack       string `json:"stack"`      // Error.stack (optional)
+	Source      string `json:"source"`     // URL of the script that threw (optional)
+	Line        int32  `json:"line"`       // (optional)
+	Column      int32  `json:"column"`     // (optional)
+	UserAgent   string `json:"user_agent"` // defaults to the User-Agent header
+}
+
+// POST /client/error
+// Reports are rate limited per client. Beacon bodies are accepted like for /client/view
+// and answered with 204 No Content.
+func ClientErrorHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		defer r.Body.Close()
+
+		errorRequest := ErrorRequest{}
+		beacon, err := decodeClientRequest(w, r, &errorRequest)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to decode error request")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		log.Debug().
+			Str("client_id", errorRequest.ClientID).
+			Str("url", errorRequest.URL).
+			Str("message", errorRequest.Message).
+			Msg("Error Request Received")
+
+		// Verify client credentials
+		clientID, err := randflake.DecodeString(errorRequest.ClientID)
+		if err != nil {
+			log.Debug().
+				Str("client_id", errorRequest.ClientID).
+				Err(err).
+				Msg("Failed to decode client ID")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid client_id"})
+			return
+		}
+
+		ok, err := is.ClientVerifyToken(r.Context(), clientID, errorRequest.ClientToken)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to verify client token")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if !ok {
+			log.Debug().
+				Str("client_id", errorRequest.ClientID).
+				Msg("Client token verification failed")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"status": "unauthorized"})
+			return
+		}
+
+		if !is.AllowErrorReport(clientID) {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]string{"error": "too many error reports"})
+			return
+		}
+
+		normalizedURL, err := is.NormalizeURL(errorRequest.URL)
+		if err != nil {
+			log.Debug().
+				Str("url", errorRequest.URL).
+				Err(err).
+				Msg("failed to normalize url")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid url"})
+			return
+		}
+
+		userAgent := errorRequest.UserAgent
+		if userAgent == "" {
+			userAgent = r.UserAgent()
+		}
+
+		report, err := core.NewErrorReport(
+			errorRequest.Message,
+			errorRequest.Stack,
+			errorRequest.Source,
+			errorRequest.Line,
+			errorRequest.Column,
+			userAgent,
+			normalizedURL,
+		)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "message is required"})
+			return
+		}
+
+		if clientBlocked(is, r, clientID) {
+			writeShadowBanned(w, beacon)
+			return
+		}
+
+		// Generate IDs for the error group and the URL (in case we need to create them)
+		groupID, err := is.GenerateID()
+		if err != nil {
+			log.Error().Err(err).Msg("failed to generate error group ID")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		urlID, err := is.GenerateID()
+		if err != nil {
+			log.Error().Err(err).Msg("failed to generate URL ID")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		err = is.ErrorReportInsert(r.Context(), groupID, urlID, report)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to insert error report")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if beacon {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	}
+}
+
+// GET /admin/v1/errors?site=<host>&from=<time>&to=<time>&limit=<n>
+//
+// Lists error groups last seen in the range, most frequent first. Counts are totals since
+// first seen. With site, only groups reported on pages of the site are listed, together with
+// those pages. Requires the read:stats scope.
+func AdminErrorsHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			log.Debug().Err(err).Msg("failed to parse time range")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid time range"})
+			return
+		}
+
+		site, err := parseSiteParam(is, r)
+		if err != nil {
+			log.Debug().Err(err).Msg("failed to parse site")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid site"})
+			return
+		}
+
+		groups, err := is.ErrorGroupList(r.Context(), site, from, to, int32(parseLimit(r, 50, 500)), 10)
+		if err != nil {
+			log.Error().Err(err).Str("site", site).Msg("failed to list error groups")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "internal error"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(types.ErrorGroupsResponse{
+			Site:   site,
+			From:   from,
+			To:     to,
+			Groups: groups,
+		})
+	}
+}
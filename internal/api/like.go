@@ -8,7 +8,6 @@ import (
 	"github.com/julienschmidt/httprouter"
 	"github.com/rs/zerolog/log"
 	"gosuda.org/randflake"
-	"telemetry.gosuda.org/telemetry/internal/core"
 	"telemetry.gosuda.org/telemetry/internal/types"
 )
 
@@ -45,7 +44,7 @@ func ClientLikeHandler(is types.InternalServiceProvider) httprouter.Handle {
 			Msg("Like Request Received")
 
 		// Normalize URL (host + pathname)
-		normalizedURL, err := core.NormalizeURL(likeRequest.URL)
+		normalizedURL, err := is.NormalizeURL(likeRequest.URL)
 		if err != nil {
 			log.Debug().
 				Str("url", likeRequest.URL).
@@ -84,6 +83,12 @@ func ClientLikeHandler(is types.InternalServiceProvider) httprouter.Handle {
 			return
 		}
 
+		if clientBlocked(is, r, clientID) {
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(LikeResponse{Status: "ok"})
+			return
+		}
+
 		// Generate ID for the like
 		likeID, err := is.GenerateID()
 		if err != nil {
@@ -92,27 +97,12 @@ func ClientLikeHandler(is types.InternalServiceProvider) httprouter.Handle {
 			return
 		}
 
-		// Look up or create URL
-		var urlID int64
-		urlRecord, err := is.UrlLookupByUrl(context.Background(), normalizedURL)
+		// Generate ID for the URL (in case we need to create one)
+		urlID, err := is.GenerateID()
 		if err != nil {
-			// URL doesn't exist, create it
-			urlID, err = is.GenerateID()
-			if err != nil {
-				log.Error().Err(err).Msg("failed to generate URL ID")
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-
-			err = is.UrlInsert(context.Background(), urlID, normalizedURL)
-			if err != nil {
-				log.Error().Err(err).Msg("failed to insert URL")
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-		} else {
-			// URL exists, use its ID
-			urlID = urlRecord.ID
+			log.Error().Err(err).Msg("failed to generate URL ID")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
 		}
 
 		// Generate ID for like count (in case we need to create one)
@@ -124,12 +114,13 @@ func ClientLikeHandler(is types.InternalServiceProvider) httprouter.Handle {
 		}
 
 		// Insert the like and update the count in a transaction
-		err = is.LikeInsertWithCount(context.Background(), likeID, urlID, clientID, likeCountID)
+		canonicalURL, err := is.LikeInsertWithCount(context.Background(), likeID, normalizedURL, clientID, urlID, likeCountID)
 		if err != nil {
 			log.Error().Err(err).Msg("failed to insert like and update count")
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
+		is.LiveNotify(canonicalURL)
 
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(LikeResponse{Status: "ok"})
@@ -157,7 +148,7 @@ func LikeCountHandler(is types.InternalServiceProvider) httprouter.Handle {
 			return
 		}
 
-		normalizedURL, err := core.NormalizeURL(rawURL)
+		normalizedURL, err := is.NormalizeURL(rawURL)
 		if err != nil {
 			log.Debug().
 				Str("url", rawURL).
@@ -185,7 +176,7 @@ func LikeCountHandler(is types.InternalServiceProvider) httprouter.Handle {
 		}
 
 		// Look up like count
-		likeCount, err := is.LikeCountLookup(context.Background(), urlRecord.ID)
+		likeCount, err := is.LikeCountDisplay(context.Background(), urlRecord.ID)
 		if err != nil {
 			log.Debug().
 				Str("url", normalizedURL).
@@ -203,7 +194,7 @@ func LikeCountHandler(is types.InternalServiceProvider) httprouter.Handle {
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(LikeCountResponse{
 			URL:   normalizedURL,
-			Count: likeCount.Count,
+			Count: likeCount,
 		})
 	}
 }
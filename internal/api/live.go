@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+const (
+	_LIVE_KEEPALIVE_INTERVAL = 25 * time.Second
+	_LIVE_RETRY_MS           = 5000
+)
+
+// GET /live?url=<url>
+//
+// Server-Sent Events stream of "update" events carrying the number of live readers
+// and the view and like counts of a URL, sent whenever one of them changes.
+func LiveHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+
+		urlRecord, ok := lookupStatsURL(is, w, r)
+		if !ok {
+			return
+		}
+
+		updates, cancel, err := is.LiveSubscribe(urlRecord.Url, urlRecord.ID, remoteIP(r))
+		if err != nil {
+			if errors.Is(err, core.ErrTooManyConnections) {
+				w.WriteHeader(http.StatusTooManyRequests)
+				json.NewEncoder(w).Encode(map[string]string{"error": "too many connections"})
+				return
+			}
+			log.Warn().Err(err).Str("url", urlRecord.Url).Msg("failed to subscribe to live updates")
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]string{"error": "live updates unavailable"})
+			return
+		}
+		defer cancel()
+
+		// The stream outlives any write timeout of the server
+		rc := http.NewResponseController(w)
+		rc.SetWriteDeadline(time.Time{})
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "retry: %d\n\n", _LIVE_RETRY_MS)
+		if err := rc.Flush(); err != nil {
+			log.Debug().Err(err).Msg("live stream does not support flushing")
+			return
+		}
+
+		keepalive := time.NewTicker(_LIVE_KEEPALIVE_INTERVAL)
+		defer keepalive.Stop()
+
+		for {
+			select {
+			case update, ok := <-updates:
+				if !ok {
+					// The server is stopping; clients reconnect to another node
+					return
+				}
+				data, _ := json.Marshal(update)
+				fmt.Fprintf(w, "event: update\ndata: %s\n\n", data)
+			case <-keepalive.C:
+				fmt.Fprint(w, ": keepalive\n\n")
+			case <-r.Context().Done():
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"gosuda.org/randflake"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+const _PURGE_MAX_CLIENTS = 1000
+
+// PurgeRequest is the optional body of purge requests
+type PurgeRequest struct {
+	ClientIDs []string `json:"client_ids"`
+}
+
+// purgeAudit is the state recorded in the audit log for a purge
+type purgeAudit struct {
+	Count     int64    `json:"count"`
+	Deleted   int64    `json:"deleted,omitempty"`
+	From      int64    `json:"from,omitempty"`
+	To        int64    `json:"to,omitempty"`
+	ClientIDs []string `json:"client_ids,omitempty"`
+}
+
+// POST /admin/v1/views/purge?url=<url>&from=<time>&to=<time>
+//
+// Deletes the views of a URL in the time range, optionally only those of the client
+// ids in the JSON body, and recomputes its view count. Requires write:moderation.
+func PurgeViewsHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return purgeHandler(is, types.AuditViewsPurge, is.ViewPurge)
+}
+
+// POST /admin/v1/likes/purge?url=<url>&from=<time>&to=<time>
+//
+// Deletes the likes of a URL in the time range, optionally only those of the client
+// ids in the JSON body, and recomputes its like count. Requires write:moderation.
+func PurgeLikesHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return purgeHandler(is, types.AuditLikesPurge, is.LikePurge)
+}
+
+// purgeHandler handles a purge request. To protect against purging a URL by accident,
+// a time range or client ids are required.
+func purgeHandler(is types.InternalServiceProvider, action string, purge func(ctx context.Context, filter types.PurgeFilter) (types.PurgeResult, error)) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		defer r.Body.Close()
+
+		var req PurgeRequest
+		err := json.NewDecoder(http.MaxBytesReader(w, r.Body, _CLIENT_MAX_BODY_SIZE)).Decode(&req)
+		if err != nil && !errors.Is(err, io.EOF) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+			return
+		}
+		if len(req.ClientIDs) > _PURGE_MAX_CLIENTS {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "too many client ids"})
+			return
+		}
+
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid time range"})
+			return
+		}
+		q := r.URL.Query()
+		if q.Get("from") == "" && q.Get("to") == "" && len(req.ClientIDs) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "a time range or client ids are required"})
+			return
+		}
+
+		// Without from, a purge of client ids covers the raw views that are still
+		// retained rather than failing on the rolled up days before them
+		filter := types.PurgeFilter{From: from, To: to, SkipRolledUp: q.Get("from") == "" && len(req.ClientIDs) > 0}
+		for _, s := range req.ClientIDs {
+			id, err := randflake.DecodeString(s)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid client id " + s})
+				return
+			}
+			filter.ClientIDs = append(filter.ClientIDs, id)
+		}
+
+		urlRecord, ok := lookupStatsURL(is, w, r)
+		if !ok {
+			return
+		}
+		filter.UrlID = urlRecord.ID
+
+		result, err := purge(r.Context(), filter)
+		if errors.Is(err, types.ErrPurgeRolledUp) {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"error": "the range covers days whose views were rolled up; pass a later from"})
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Str("url", urlRecord.Url).Str("action", action).Msg("failed to purge")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "internal error"})
+			return
+		}
+		result.URL = urlRecord.Url
+
+		is.LiveNotify(urlRecord.Url)
+		if !recordAudit(is, w, r, action, urlRecord.Url,
+			purgeAudit{Count: result.CountBefore},
+			purgeAudit{Count: result.CountAfter, Deleted: result.Deleted, From: result.From, To: result.To, ClientIDs: req.ClientIDs}) {
+			return
+		}
+
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+// PUT /admin/v1/offset?url=<url>
+//
+// Sets the offsets added to the displayed view and like counts of a URL from a JSON
+// types.CountOffset body, e.g. to carry over historical counts. Zero offsets remove
+// them. Requires write:moderation.
+func CountOffsetHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		defer r.Body.Close()
+
+		var offset types.CountOffset
+		err := json.NewDecoder(http.MaxBytesReader(w, r.Body, _CLIENT_MAX_BODY_SIZE)).Decode(&offset)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		urlRecord, ok := lookupStatsURL(is, w, r)
+		if !ok {
+			return
+		}
+
+		before, err := is.CountOffsetLookup(r.Context(), urlRecord.ID)
+		if err == nil {
+			err = is.CountOffsetSet(r.Context(), urlRecord.ID, offset)
+		}
+		if err != nil {
+			log.Error().Err(err).Str("url", urlRecord.Url).Msg("failed to set count offset")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "internal error"})
+			return
+		}
+
+		is.LiveNotify(urlRecord.Url)
+		if !recordAudit(is, w, r, types.AuditCountOffsetSet, urlRecord.Url, before, offset) {
+			return
+		}
+
+		json.NewEncoder(w).Encode(offset)
+	}
+}
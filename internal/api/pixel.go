@@ -0,0 +1,96 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// 1x1 transparent GIF
+var _pixel_gif = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// remoteIP returns the IP address of the request without the port
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// GET /p.gif?url=<url>
+// Records an anonymous view for readers without JavaScript, such as feed readers. The url
+// parameter defaults to the Referer header. Crawlers are ignored and views are deduplicated
+// per URL and day by a keyed hash of the IP address. Views from blocked IP addresses and
+// User-Agents are dropped. The pixel is returned in any case.
+func PixelHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "image/gif")
+		w.Header().Set("Cache-Control", "no-store, max-age=0")
+		w.WriteHeader(http.StatusOK)
+		w.Write(_pixel_gif)
+
+		rawURL := r.URL.Query().Get("url")
+		if rawURL == "" {
+			rawURL = r.Referer()
+		}
+
+		userAgent := r.UserAgent()
+		if rawURL == "" || userAgent == "" || core.IsCrawler(userAgent) {
+			return
+		}
+		if clientBlocked(is, r, 0) {
+			return
+		}
+
+		normalizedURL, err := is.NormalizeURL(rawURL)
+		if err != nil {
+			log.Debug().
+				Str("url", rawURL).
+				Err(err).
+				Msg("failed to normalize url")
+			return
+		}
+
+		// Generate IDs for the view, its URL and count (in case we need to create them)
+		ids := make([]int64, 3)
+		for i := range ids {
+			ids[i], err = is.GenerateID()
+			if err != nil {
+				log.Error().Err(err).Msg("failed to generate pixel view ID")
+				return
+			}
+		}
+
+		// The page embedding the pixel is the referrer of the image request, not of the page view
+		view := types.ViewEvent{
+			ID:       ids[0],
+			URL:      normalizedURL,
+			Campaign: core.ExtractCampaign(rawURL),
+		}
+
+		day := core.UTCDay(time.Now().UnixNano())
+		canonicalURL, recorded, err := is.ViewInsertAnonymous(r.Context(), view, is.AnonymousIPHash(remoteIP(r), day), day, ids[1], ids[2])
+		if err != nil {
+			log.Error().Err(err).Msg("failed to insert pixel view")
+			return
+		}
+		if recorded {
+			is.LiveNotify(canonicalURL)
+		}
+
+		log.Debug().
+			Str("url", normalizedURL).
+			Bool("recorded", recorded).
+			Msg("Pixel View Received")
+	}
+}
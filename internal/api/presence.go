@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+const (
+	_PRESENCE_PING_INTERVAL = 30 * time.Second
+	_PRESENCE_IDLE_TIMEOUT  = 60 * time.Second // without a heartbeat the reader is no longer present
+	_PRESENCE_WRITE_TIMEOUT = 10 * time.Second
+	_PRESENCE_MAX_MESSAGE   = 4096
+)
+
+// _origin_pattern_escaper escapes a host for use as a path.Match pattern, e.g. an IPv6 literal
+var _origin_pattern_escaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// PresenceMessage is sent to presence connections. "presence" messages carry the
+// live state of the URL, "like" messages are sent when its like count increased.
+type PresenceMessage struct {
+	Type string `json:"type"`
+	types.LiveUpdate
+}
+
+// presenceClientMessage is sent by presence clients; only "heartbeat" is understood
+type presenceClientMessage struct {
+	Type string `json:"type"`
+}
+
+// GET /live/ws?url=<url>
+//
+// WebSocket presence channel. The reader counts as present while it sends a
+// {"type":"heartbeat"} message at least every 60 seconds. The server pushes
+// PresenceMessages and closes the connection with code 1012 when it shuts down,
+// 1008 if the address has too many live connections and 1013 if live updates are
+// unavailable.
+func PresenceHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+
+		urlRecord, ok := lookupStatsURL(is, w, r)
+		if !ok {
+			return
+		}
+
+		// Only pages of the site of the URL may open its presence channel. The handshake
+		// comes first so that rejected requests take no connection slot and no presence.
+		site := _origin_pattern_escaper.Replace(core.URLSite(urlRecord.Url))
+		c, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+			OriginPatterns: []string{site, "www." + site},
+		})
+		if err != nil {
+			log.Debug().Err(err).Str("origin", r.Header.Get("Origin")).Msg("failed to accept presence connection")
+			return
+		}
+		defer c.CloseNow()
+		c.SetReadLimit(_PRESENCE_MAX_MESSAGE)
+
+		// Blocked readers get the usual updates but are not counted as present
+		join := is.LiveJoin
+		if clientBlocked(is, r, 0) {
+			join = is.LiveSubscribe
+		}
+		updates, leave, err := join(urlRecord.Url, urlRecord.ID, remoteIP(r))
+		if err != nil {
+			if errors.Is(err, core.ErrTooManyConnections) {
+				c.Close(websocket.StatusPolicyViolation, "too many connections")
+				return
+			}
+			log.Warn().Err(err).Str("url", urlRecord.Url).Msg("failed to join presence")
+			c.Close(websocket.StatusTryAgainLater, "live updates unavailable")
+			return
+		}
+		defer leave()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		heartbeats := make(chan struct{}, 1)
+		readErr := make(chan error, 1)
+		go func() {
+			for {
+				// Readers that stay silent past the idle timeout are disconnected
+				readCtx, readCancel := context.WithTimeout(ctx, _PRESENCE_IDLE_TIMEOUT)
+				_, message, err := c.Read(readCtx)
+				readCancel()
+				if err != nil {
+					readErr <- err
+					return
+				}
+				var m presenceClientMessage
+				if json.Unmarshal(message, &m) == nil && m.Type == "heartbeat" {
+					select {
+					case heartbeats <- struct{}{}:
+					default:
+					}
+				}
+			}
+		}()
+
+		ping := time.NewTicker(_PRESENCE_PING_INTERVAL)
+		defer ping.Stop()
+
+		lastHeartbeat := time.Now()
+		var last types.LiveUpdate
+		sent := false
+		for {
+			var err error
+			select {
+			case update, ok := <-updates:
+				if !ok {
+					// The server is stopping; clients reconnect to another node
+					c.Close(websocket.StatusServiceRestart, "server restarting")
+					return
+				}
+				if sent && update.Likes > last.Likes {
+					err = writePresenceMessage(ctx, c, PresenceMessage{Type: "like", LiveUpdate: update})
+				}
+				if err == nil {
+					err = writePresenceMessage(ctx, c, PresenceMessage{Type: "presence", LiveUpdate: update})
+				}
+				last, sent = update, true
+			case <-heartbeats:
+				lastHeartbeat = time.Now()
+			case <-ping.C:
+				if time.Since(lastHeartbeat) > _PRESENCE_IDLE_TIMEOUT {
+					c.Close(websocket.StatusNormalClosure, "idle")
+					return
+				}
+				pingCtx, pingCancel := context.WithTimeout(ctx, _PRESENCE_WRITE_TIMEOUT)
+				err = c.Ping(pingCtx)
+				pingCancel()
+			case err := <-readErr:
+				// The library answers close frames and protocol errors itself
+				log.Debug().Err(err).Msg("presence connection closed")
+				return
+			}
+			if err != nil {
+				log.Debug().Err(err).Msg("failed to write presence message")
+				return
+			}
+		}
+	}
+}
+
+func writePresenceMessage(ctx context.Context, c *websocket.Conn, m PresenceMessage) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, _PRESENCE_WRITE_TIMEOUT)
+	defer cancel()
+	return c.Write(ctx, websocket.MessageText, data)
+}
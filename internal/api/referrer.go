@@ -0,0 +1,46 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// GET /stats/referrers?url=<url>&from=<time>&to=<time>&limit=<n>
+func StatsReferrersHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "max-age=60, stale-while-revalidate=86400")
+
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			log.Debug().Err(err).Msg("failed to parse time range")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid time range"})
+			return
+		}
+
+		urlRecord, ok := lookupStatsURL(is, w, r)
+		if !ok {
+			return
+		}
+
+		results, err := is.ReferrerStatsByUrl(r.Context(), urlRecord.ID, from, to, int32(parseLimit(r, 20, 100)))
+		if err != nil {
+			log.Error().Err(err).Int64("url_id", urlRecord.ID).Msg("failed to query referrer stats")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(types.ReferrerStatsResponse{
+			URL:     urlRecord.Url,
+			From:    from,
+			To:      to,
+			Results: results,
+		})
+	}
+}
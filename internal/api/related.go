@@ -0,0 +1,38 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// GET /related?url=<url>&limit=<n>
+func RelatedHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "max-age=300, stale-while-revalidate=86400")
+
+		urlRecord, ok := lookupStatsURL(is, w, r)
+		if !ok {
+			return
+		}
+
+		// Only recommend pages of the same site
+		related, err := is.RelatedByUrl(r.Context(), urlRecord.ID, core.URLSite(urlRecord.Url), int32(parseLimit(r, 5, 20)))
+		if err != nil {
+			log.Error().Err(err).Int64("url_id", urlRecord.ID).Msg("failed to query related urls")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(types.RelatedResponse{
+			URL:     urlRecord.Url,
+			Related: related,
+		})
+	}
+}
@@ -9,16 +9,23 @@ import (
 
 // RegisterRoutes registers all API routes with the server and returns the server
 func RegisterRoutes(s *httprouter.Router, is types.InternalServiceProvider) {
+	// the SDK scripts are compressed once and shared by the index page and the SDK routes
+	sdk := sdkAssets(is.PublicBaseURL())
+
 	// index
-	s.Handle("GET", "/", IndexHandler(is))
+	s.Handle("GET", "/", IndexHandler(is, sdk))
+
+	// browser SDK
+	registerSDKRoutes(s, sdk)
 
 	// go package
 	s.Handle("GET", "/telemetry", GoPackageHandler(is))
 
 	// z-routes
 	s.Handle("GET", "/healthz", HealthzHandler(is))
-	s.Handle("GET", "/idz", IDzHandler(is))
-	s.Handle("GET", "/getz", GetzHandler(is))
+
+	// admin routes, authenticated with API keys
+	registerAdminRoutes(s, is)
 
 	// telemetry routes
 	s.Handle("POST", "/client/status", ClientStatusHandler(is))
@@ -26,6 +33,11 @@ func RegisterRoutes(s *httprouter.Router, is types.InternalServiceProvider) {
 	s.Handle("POST", "/client/checkin", ClientCheckinHandler(is))
 	s.Handle("POST", "/client/view", ClientViewHandler(is))
 	s.Handle("POST", "/client/like", ClientLikeHandler(is))
+	s.Handle("POST", "/client/event", ClientEventHandler(is))
+	s.Handle("POST", "/client/events", ClientEventsHandler(is))
+	s.Handle("POST", "/client/engagement", ClientEngagementHandler(is))
+	s.Handle("POST", "/client/vitals", ClientVitalsHandler(is))
+	s.Handle("POST", "/client/error", ClientErrorHandler(is))
 
 	// bulk counts endpoint (POST body: JSON { "urls": ["https://...","..."] })
 	s.Handle("POST", "/counts/bulk", BulkCountsHandler(is))
@@ -34,6 +46,24 @@ func RegisterRoutes(s *httprouter.Router, is types.InternalServiceProvider) {
 	s.Handle("GET", "/view/count", ViewCountHandler(is))
 	s.Handle("GET", "/like/count", LikeCountHandler(is))
 
+	// no-JavaScript tracking pixel and count badges
+	s.Handle("GET", "/p.gif", PixelHandler(is))
+	s.Handle("GET", "/badge/views.svg", ViewsBadgeHandler(is))
+	s.Handle("GET", "/badge/likes.svg", LikesBadgeHandler(is))
+	s.Handle("GET", "/badge/sparkline.svg", SparklineHandler(is))
+
+	// stats routes
+	s.Handle("GET", "/stats/devices", StatsDevicesHandler(is))
+	s.Handle("GET", "/stats/referrers", StatsReferrersHandler(is))
+	s.Handle("GET", "/stats/campaigns", StatsCampaignsHandler(is))
+	s.Handle("GET", "/top", TopHandler(is))
+	s.Handle("GET", "/related", RelatedHandler(is))
+	s.Handle("GET", "/live", LiveHandler(is))
+	s.Handle("GET", "/live/ws", PresenceHandler(is))
+	s.Handle("GET", "/stats/engagement", StatsEngagementHandler(is))
+	s.Handle("GET", "/stats/events", StatsEventsHandler(is))
+	s.Handle("GET", "/stats/vitals", StatsVitalsHandler(is))
+
 	// generate 204
 	s.Handle("GET", "/generate_204", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 		w.WriteHeader(http.StatusNoContent)
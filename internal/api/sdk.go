@@ -0,0 +1,137 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/julienschmidt/httprouter"
+	"telemetry.gosuda.org/telemetry"
+)
+
+// _SDK_VERSION is part of the SDK URLs, which are cached as immutable.
+// Bump it whenever client.js or fpid.js change in a way that breaks pages pinning the old integrity hash.
+const _SDK_VERSION = 2
+
+var _sdk_baseurl_pattern = regexp.MustCompile(`(?m)^const TELEMETRY_BASEURL = .*;$`)
+
+// sdkAsset is a prepared SDK script with its precompressed forms
+type sdkAsset struct {
+	name      string
+	body      []byte
+	gzipped   []byte
+	brotli    []byte
+	etag      string
+	integrity string // Subresource Integrity hash of body
+}
+
+// sdkAssets returns the SDK scripts with the TELEMETRY_BASEURL of their config block
+// replaced by baseURL. An empty baseURL keeps the embedded default.
+func sdkAssets(baseURL string) []sdkAsset {
+	sources := []struct {
+		name string
+		src  []byte
+	}{
+		{"telemetry.js", telemetry.ClientJS},
+		{"fpid.js", telemetry.FPIDJS},
+	}
+
+	assets := make([]sdkAsset, 0, len(sources))
+	for _, s := range sources {
+		body := s.src
+		if baseURL != "" {
+			quoted, _ := json.Marshal(strings.TrimSuffix(baseURL, "/"))
+			body = _sdk_baseurl_pattern.ReplaceAllLiteral(body, []byte(fmt.Sprintf("const TELEMETRY_BASEURL = %s;", quoted)))
+		}
+
+		var gz bytes.Buffer
+		zw, _ := gzip.NewWriterLevel(&gz, gzip.BestCompression)
+		zw.Write(body)
+		zw.Close()
+
+		var br bytes.Buffer
+		bw := brotli.NewWriterLevel(&br, brotli.BestCompression)
+		bw.Write(body)
+		bw.Close()
+
+		sum := sha256.Sum256(body)
+		sri := sha512.Sum384(body)
+		assets = append(assets, sdkAsset{
+			name:      s.name,
+			body:      body,
+			gzipped:   gz.Bytes(),
+			brotli:    br.Bytes(),
+			etag:      hex.EncodeToString(sum[:16]),
+			integrity: "sha384-" + base64.StdEncoding.EncodeToString(sri[:]),
+		})
+	}
+	return assets
+}
+
+// sdkPath returns the URL path of an SDK script
+func sdkPath(name string) string {
+	return fmt.Sprintf("/sdk/v%d/%s", _SDK_VERSION, name)
+}
+
+// acceptsEncoding reports whether the client accepts the given content encoding
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.EqualFold(strings.TrimSpace(coding), encoding) {
+			return strings.ReplaceAll(params, " ", "") != "q=0"
+		}
+	}
+	return false
+}
+
+// GET /sdk/v{N}/<name>
+func SDKHandler(asset sdkAsset) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		body := asset.body
+		etag := `"` + asset.etag + `"`
+		switch {
+		case acceptsEncoding(r, "br"):
+			body = asset.brotli
+			etag = `"` + asset.etag + `-br"`
+			w.Header().Set("Content-Encoding", "br")
+		case acceptsEncoding(r, "gzip"):
+			body = asset.gzipped
+			etag = `"` + asset.etag + `-gz"`
+			w.Header().Set("Content-Encoding", "gzip")
+		}
+
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Header().Add("Vary", "Accept-Encoding")
+		w.Header().Set("ETag", etag)
+
+		if match := r.Header.Get("If-None-Match"); match != "" && (match == "*" || strings.Contains(match, etag)) {
+			w.Header().Del("Content-Encoding")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.WriteHeader(http.StatusOK)
+		if r.Method != http.MethodHead {
+			w.Write(body)
+		}
+	}
+}
+
+// registerSDKRoutes serves the SDK scripts prepared by sdkAssets
+func registerSDKRoutes(s *httprouter.Router, assets []sdkAsset) {
+	for _, asset := range assets {
+		s.Handle("GET", sdkPath(asset.name), SDKHandler(asset))
+		s.Handle("HEAD", sdkPath(asset.name), SDKHandler(asset))
+	}
+}
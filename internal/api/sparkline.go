@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+const (
+	_SPARKLINE_DEFAULT_DAYS = 30
+	_SPARKLINE_MAX_DAYS     = 365
+)
+
+// GET /badge/sparkline.svg?url=<url>&days=<n>&width=<px>&height=<px>&color=<color>&fill=<color|none>&stroke_width=<px>
+func SparklineHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		q := r.URL.Query()
+
+		days, opts, err := parseSparklineOptions(r)
+		if err != nil {
+			log.Debug().Err(err).Msg("invalid sparkline options")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid days, size or color"})
+			return
+		}
+
+		rawURL := q.Get("url")
+		if rawURL == "" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "url parameter is required"})
+			return
+		}
+
+		normalizedURL, err := is.NormalizeURL(rawURL)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid url"})
+			return
+		}
+
+		// The last day is today, so the line moves as views come in
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		fromDay := today.AddDate(0, 0, 1-days).UnixNano()
+
+		// Unknown URLs are drawn as a flat line like badges show 0
+		counts := make([]int64, days)
+		urlRecord, err := is.UrlLookupByUrl(r.Context(), normalizedURL)
+		if err == nil {
+			counts, err = is.ViewDailyCounts(r.Context(), urlRecord.ID, fromDay, days)
+			if err != nil {
+				log.Error().Err(err).Int64("url_id", urlRecord.ID).Msg("failed to query daily views")
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+		w.Header().Set("Cache-Control", "max-age=300, stale-while-revalidate=3600")
+		w.WriteHeader(http.StatusOK)
+		w.Write(core.RenderSparkline(counts, opts))
+	}
+}
+
+// parseSparklineOptions reads the number of days and the validated look of a sparkline request
+func parseSparklineOptions(r *http.Request) (int, core.SparklineOptions, error) {
+	q := r.URL.Query()
+	opts := core.SparklineOptions{
+		Color: q.Get("color"),
+		Fill:  q.Get("fill"),
+	}
+
+	atoi := func(name string, def int) (int, error) {
+		if v := q.Get(name); v != "" {
+			return strconv.Atoi(v)
+		}
+		return def, nil
+	}
+
+	days, err := atoi("days", _SPARKLINE_DEFAULT_DAYS)
+	if err != nil {
+		return 0, opts, err
+	}
+	if days < 2 || days > _SPARKLINE_MAX_DAYS {
+		return 0, opts, errInvalidRange
+	}
+	if opts.Width, err = atoi("width", 0); err != nil {
+		return 0, opts, err
+	}
+	if opts.Height, err = atoi("height", 0); err != nil {
+		return 0, opts, err
+	}
+	if opts.StrokeWidth, err = atoi("stroke_width", 0); err != nil {
+		return 0, opts, err
+	}
+	return days, opts, opts.Validate()
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// lookupStatsURL normalizes the url query parameter of a stats request and
+// looks up the stored URL record. On failure it writes the error response
+// and returns false.
+func lookupStatsURL(is types.InternalServiceProvider, w http.ResponseWriter, r *http.Request) (types.Url, bool) {
+	rawURL := r.URL.Query().Get("url")
+	if rawURL == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "url parameter is required"})
+		return types.Url{}, false
+	}
+
+	normalizedURL, err := is.NormalizeURL(rawURL)
+	if err != nil {
+		log.Debug().
+			Str("url", rawURL).
+			Err(err).
+			Msg("failed to normalize url")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid url"})
+		return types.Url{}, false
+	}
+
+	urlRecord, err := is.UrlLookupByUrl(r.Context(), normalizedURL)
+	if err != nil {
+		log.Debug().
+			Str("url", normalizedURL).
+			Err(err).
+			Msg("URL not found")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "URL not found"})
+		return types.Url{}, false
+	}
+
+	return urlRecord, true
+}
+
+var (
+	errInvalidTime  = errors.New("invalid time")
+	errInvalidRange = errors.New("invalid time range")
+)
+
+// parseStatsTime parses a time query parameter given as RFC 3339, a date
+// (YYYY-MM-DD) or unix seconds. Dates are returned together with their length
+// so an inclusive "to" date can cover the whole day.
+func parseStatsTime(v string) (time.Time, time.Duration, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, 0, nil
+	}
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, 24 * time.Hour, nil
+	}
+	if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(sec, 0), 0, nil
+	}
+	return time.Time{}, 0, errInvalidTime
+}
+
+// parseTimeRange reads the optional from and to query parameters and returns
+// the range [from, to) in unix nanoseconds. The range defaults to all time.
+func parseTimeRange(r *http.Request) (int64, int64, error) {
+	from := int64(0)
+	to := time.Now().UnixNano() + 1
+
+	if v := r.URL.Query().Get("from"); v != "" {
+		t, _, err := parseStatsTime(v)
+		if err != nil {
+			return 0, 0, err
+		}
+		from = t.UnixNano()
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		t, span, err := parseStatsTime(v)
+		if err != nil {
+			return 0, 0, err
+		}
+		to = t.Add(span).UnixNano()
+	}
+	if from >= to {
+		return 0, 0, errInvalidRange
+	}
+	return from, to, nil
+}
+
+// parseSiteParam reads the optional site query parameter and normalizes it
+// to the host part of a normalized URL. An empty site means all sites.
+func parseSiteParam(is types.InternalServiceProvider, r *http.Request) (string, error) {
+	site := r.URL.Query().Get("site")
+	if site == "" {
+		return "", nil
+	}
+	normalized, err := is.NormalizeURL(site)
+	if err != nil {
+		return "", err
+	}
+	return core.URLSite(normalized), nil
+}
+
+// parseLimit reads the optional limit query parameter, clamped to [1, max]
+func parseLimit(r *http.Request, def int, max int) int {
+	v, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || v <= 0 {
+		return def
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// dimensionCounter accumulates counts per dimension value
+type dimensionCounter map[string]int64
+
+// sorted returns the counts ordered by count descending, then name ascending
+func (d dimensionCounter) sorted() []types.DimensionCount {
+	out := make([]types.DimensionCount, 0, len(d))
+	for name, count := range d {
+		out = append(out, types.DimensionCount{Name: name, Count: count})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// GET /stats/devices?url=<url>
+func StatsDevicesHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "max-age=60, stale-while-revalidate=86400")
+
+		urlRecord, ok := lookupStatsURL(is, w, r)
+		if !ok {
+			return
+		}
+
+		rows, err := is.DeviceStatsByUrl(r.Context(), urlRecord.ID)
+		if err != nil {
+			log.Error().Err(err).Int64("url_id", urlRecord.ID).Msg("failed to query device stats")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		browsers := dimensionCounter{}
+		browserVersions := dimensionCounter{}
+		oses := dimensionCounter{}
+		deviceClasses := dimensionCounter{}
+		mobile := dimensionCounter{}
+
+		resp := types.DeviceStatsResponse{URL: urlRecord.Url}
+		for _, row := range rows {
+			resp.Total += row.Count
+			browsers[row.Browser] += row.Count
+			if row.BrowserVersion != "" {
+				browserVersions[fmt.Sprintf("%s %s", row.Browser, row.BrowserVersion)] += row.Count
+			} else {
+				browserVersions[row.Browser] += row.Count
+			}
+			oses[row.OS] += row.Count
+			deviceClasses[row.DeviceClass] += row.Count
+			if row.Mobile {
+				mobile["mobile"] += row.Count
+			} else {
+				mobile["non-mobile"] += row.Count
+			}
+		}
+		resp.Browsers = browsers.sorted()
+		resp.BrowserVersions = browserVersions.sorted()
+		resp.OS = oses.sorted()
+		resp.DeviceClasses = deviceClasses.sorted()
+		resp.Mobile = mobile.sorted()
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
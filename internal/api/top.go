@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// GET /top?site=<host>&metric=views|likes|trending&window=24h|7d|30d|all&limit=<n>
+func TopHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "max-age=60, stale-while-revalidate=600")
+
+		site, err := parseSiteParam(is, r)
+		if err != nil {
+			log.Debug().Err(err).Msg("failed to parse site")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid site"})
+			return
+		}
+
+		metricName := r.URL.Query().Get("metric")
+		if metricName == "" {
+			metricName = "views"
+		}
+		metric, err := core.RankingMetric(metricName)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "metric must be views, likes or trending"})
+			return
+		}
+
+		windowName := r.URL.Query().Get("window")
+		if windowName == "" || metric == types.RankingTrending {
+			// trending decays on its own and is only ranked over all time
+			windowName = "all"
+		}
+		window, err := core.RankingWindow(windowName)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "window must be 24h, 7d, 30d or all"})
+			return
+		}
+
+		entries, computedAt, err := is.RankingTop(r.Context(), metric, window, site, int32(parseLimit(r, 10, 100)))
+		if err != nil {
+			log.Error().Err(err).Str("site", site).Msg("failed to query rankings")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(types.TopResponse{
+			Site:       site,
+			Metric:     metricName,
+			Window:     windowName,
+			ComputedAt: computedAt,
+			Entries:    entries,
+		})
+	}
+}
@@ -18,21 +18,25 @@ type ViewRequest struct {
 	ClientID    string `json:"client_id"`    // Client's unique identifier
 	ClientToken string `json:"client_token"` // Authentication token for the client
 	URL         string `json:"url"`          // URL being viewed
+	Referrer    string `json:"referrer"`     // document.referrer of the page view (optional)
 }
 
 // ViewResponse represents the response to a page view request
 type ViewResponse struct {
 	Status string `json:"status"`
+	ViewID string `json:"view_id,omitempty"` // identifies the view in /client/engagement reports
 }
 
 // POST /client/view
+// Accepts JSON as well as text/plain and form bodies sent by navigator.sendBeacon,
+// which are answered with 204 No Content.
 func ClientViewHandler(is types.InternalServiceProvider) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 		w.Header().Set("Content-Type", "application/json")
 		defer r.Body.Close()
 
 		viewRequest := ViewRequest{}
-		err := json.NewDecoder(r.Body).Decode(&viewRequest)
+		beacon, err := decodeClientRequest(w, r, &viewRequest)
 		if err != nil {
 			log.Error().Err(err).Msg("failed to decode view request")
 			w.WriteHeader(http.StatusBadRequest)
@@ -44,10 +48,11 @@ func ClientViewHandler(is types.InternalServiceProvider) httprouter.Handle {
 			Str("client_id", viewRequest.ClientID).
 			Str("client_token", viewRequest.ClientToken).
 			Str("url", viewRequest.URL).
+			Str("referrer", viewRequest.Referrer).
 			Msg("View Request Received")
 
-		// Normalize URL (host + pathname)
-		normalizedURL, err := core.NormalizeURL(viewRequest.URL)
+		// Normalize URL (host + pathname). Campaign parameters are extracted from the raw URL below.
+		normalizedURL, err := is.NormalizeURL(viewRequest.URL)
 		if err != nil {
 			log.Debug().
 				Str("url", viewRequest.URL).
@@ -95,27 +100,12 @@ func ClientViewHandler(is types.InternalServiceProvider) httprouter.Handle {
 			return
 		}
 
-		// Look up or create URL
-		var urlID int64
-		urlRecord, err := is.UrlLookupByUrl(context.Background(), normalizedURL)
+		// Generate ID for the URL (in case we need to create one)
+		urlID, err := is.GenerateID()
 		if err != nil {
-			// URL doesn't exist, create it
-			urlID, err = is.GenerateID()
-			if err != nil {
-				log.Error().Err(err).Msg("failed to generate URL ID")
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-
-			err = is.UrlInsert(context.Background(), urlID, normalizedURL)
-			if err != nil {
-				log.Error().Err(err).Msg("failed to insert URL")
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-		} else {
-			// URL exists, use its ID
-			urlID = urlRecord.ID
+			log.Error().Err(err).Msg("failed to generate URL ID")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
 		}
 
 		// Generate ID for view count (in case we need to create one)
@@ -126,16 +116,33 @@ func ClientViewHandler(is types.InternalServiceProvider) httprouter.Handle {
 			return
 		}
 
-		// Insert the view and update the count in a transaction
-		err = is.ViewInsertWithCount(context.Background(), viewID, urlID, clientID, viewCountID)
-		if err != nil {
-			log.Error().Err(err).Msg("failed to insert view and update count")
-			w.WriteHeader(http.StatusInternalServerError)
+		view := types.ViewEvent{
+			ID:       viewID,
+			URL:      normalizedURL,
+			ClientID: clientID,
+			Referrer: core.ParseReferrer(viewRequest.Referrer, viewRequest.URL),
+			Campaign: core.ExtractCampaign(viewRequest.URL),
+		}
+
+		// Insert the view and update the count in a transaction. Views of blocked clients
+		// get the usual response, including a view ID, but are not recorded.
+		if !clientBlocked(is, r, clientID) {
+			canonicalURL, err := is.ViewInsertWithCount(context.Background(), view, urlID, viewCountID)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to insert view and update count")
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			is.LiveNotify(canonicalURL)
+		}
+
+		if beacon {
+			w.WriteHeader(http.StatusNoContent)
 			return
 		}
 
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"ok"}`))
+		json.NewEncoder(w).Encode(ViewResponse{Status: "ok", ViewID: randflake.EncodeString(viewID)})
 	}
 }
 
@@ -160,7 +167,7 @@ func ViewCountHandler(is types.InternalServiceProvider) httprouter.Handle {
 			return
 		}
 
-		normalizedURL, err := core.NormalizeURL(rawURL)
+		normalizedURL, err := is.NormalizeURL(rawURL)
 		if err != nil {
 			log.Debug().
 				Str("url", rawURL).
@@ -188,7 +195,7 @@ func ViewCountHandler(is types.InternalServiceProvider) httprouter.Handle {
 		}
 
 		// Look up view count
-		viewCount, err := is.ViewCountLookup(context.Background(), urlRecord.ID)
+		viewCount, err := is.ViewCountDisplay(context.Background(), urlRecord.ID)
 		if err != nil {
 			log.Debug().
 				Str("url", normalizedURL).
@@ -201,6 +208,6 @@ func ViewCountHandler(is types.InternalServiceProvider) httprouter.Handle {
 		}
 
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(fmt.Sprintf(`{"url":"%s","count":%d}`, normalizedURL, viewCount.Count)))
+		w.Write([]byte(fmt.Sprintf(`{"url":"%s","count":%d}`, normalizedURL, viewCount)))
 	}
 }
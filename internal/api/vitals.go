@@ -0,0 +1,166 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"gosuda.org/randflake"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// VitalsRequest carries the web vitals measured on a page load
+type VitalsRequest struct {
+	ClientID    string             `json:"client_id"`
+	ClientToken string             `json:"client_token"`
+	URL         string             `json:"url"`
+	Metrics     map[string]float64 `json:"metrics"` // LCP, INP, FCP and TTFB in milliseconds, CLS as a score
+}
+
+// POST /client/vitals
+// Beacon bodies are accepted like for /client/view and answered with 204 No Content.
+func ClientVitalsHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		defer r.Body.Close()
+
+		vitalsRequest := VitalsRequest{}
+		beacon, err := decodeClientRequest(w, r, &vitalsRequest)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to decode vitals request")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+			return
+		}
+
+		log.Debug().
+			Str("client_id", vitalsRequest.ClientID).
+			Str("url", vitalsRequest.URL).
+			Interface("metrics", vitalsRequest.Metrics).
+			Msg("Vitals Request Received")
+
+		if len(vitalsRequest.Metrics) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "metrics are required"})
+			return
+		}
+
+		measurements := make([]types.VitalMeasurement, 0, len(vitalsRequest.Metrics))
+		for name, value := range vitalsRequest.Metrics {
+			m, err := core.VitalMeasurement(name, value)
+			if err != nil {
+				log.Debug().Str("metric", name).Float64("value", value).Err(err).Msg("invalid web vital")
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid metric " + name})
+				return
+			}
+			measurements = append(measurements, m)
+		}
+
+		normalizedURL, err := is.NormalizeURL(vitalsRequest.URL)
+		if err != nil {
+			log.Debug().
+				Str("url", vitalsRequest.URL).
+				Err(err).
+				Msg("failed to normalize url")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid url"})
+			return
+		}
+
+		// Verify client credentials
+		clientID, err := randflake.DecodeString(vitalsRequest.ClientID)
+		if err != nil {
+			log.Debug().
+				Str("client_id", vitalsRequest.ClientID).
+				Err(err).
+				Msg("Failed to decode client ID")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid client_id"})
+			return
+		}
+
+		ok, err := is.ClientVerifyToken(r.Context(), clientID, vitalsRequest.ClientToken)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to verify client token")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if !ok {
+			log.Debug().
+				Str("client_id", vitalsRequest.ClientID).
+				Msg("Client token verification failed")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"status": "unauthorized"})
+			return
+		}
+
+		if clientBlocked(is, r, clientID) {
+			writeShadowBanned(w, beacon)
+			return
+		}
+
+		// Generate an ID for the URL (in case we need to create one)
+		urlID, err := is.GenerateID()
+		if err != nil {
+			log.Error().Err(err).Msg("failed to generate URL ID")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		err = is.VitalInsert(r.Context(), normalizedURL, clientID, urlID, measurements)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to insert web vitals")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if beacon {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	}
+}
+
+// GET /stats/vitals?url=<url>&from=<time>&to=<time>
+// Vitals are kept per UTC day, so from is rounded down to the start of its day.
+func StatsVitalsHandler(is types.InternalServiceProvider) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "max-age=60, stale-while-revalidate=86400")
+
+		from, to, err := parseTimeRange(r)
+		if err != nil {
+			log.Debug().Err(err).Msg("failed to parse time range")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid time range"})
+			return
+		}
+
+		urlRecord, ok := lookupStatsURL(is, w, r)
+		if !ok {
+			return
+		}
+
+		buckets, err := is.VitalBucketsByUrl(r.Context(), urlRecord.ID, from, to)
+		if err != nil {
+			log.Error().Err(err).Int64("url_id", urlRecord.ID).Msg("failed to query web vitals")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(types.VitalsStatsResponse{
+			URL:     urlRecord.Url,
+			From:    from,
+			To:      to,
+			Metrics: core.VitalStats(buckets),
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+const _API_KEY_PREFIX = "tlm_"
+
+var (
+	ErrInvalidApiKeyName  = errors.New("core: invalid api key name")
+	ErrInvalidApiKeyScope = errors.New("core: invalid api key scope")
+	ErrApiKeyNotFound     = errors.New("core: api key not found or already revoked")
+)
+
+var _api_key_name_pattern = regexp.MustCompile(`^[A-Za-z0-9_.:@-]{1,64}$`)
+
+var _api_key_scopes = []string{types.ScopeReadStats, types.ScopeWriteModeration, types.ScopeAdmin}
+
+// NewApiKey generates a random API key and returns it with its hash. The key is
+// only shown once; the database stores the hash.
+func NewApiKey() (string, []byte) {
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	key := _API_KEY_PREFIX + base64.RawURLEncoding.EncodeToString(secret)
+	return key, ApiKeyHash(key)
+}
+
+// ApiKeyHash returns the hash an API key is stored and looked up by. Keys are
+// random, so a plain SHA-256 cannot be brute forced.
+func ApiKeyHash(key string) []byte {
+	sum := sha256.Sum256([]byte(key))
+	return sum[:]
+}
+
+// ValidateApiKey checks the name and scopes of a new API key and returns the
+// scopes sorted and without duplicates
+func ValidateApiKey(name string, scopes []string) ([]string, error) {
+	if !_api_key_name_pattern.MatchString(name) {
+		return nil, ErrInvalidApiKeyName
+	}
+	if len(scopes) == 0 {
+		return nil, ErrInvalidApiKeyScope
+	}
+	for _, s := range scopes {
+		if !slices.Contains(_api_key_scopes, s) {
+			return nil, ErrInvalidApiKeyScope
+		}
+	}
+	out := slices.Clone(scopes)
+	slices.Sort(out)
+	return slices.Compact(out), nil
+}
+
+// BearerToken returns the token of an "Authorization: Bearer <token>" header value
+func BearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// CreateApiKey validates and stores a new API key and returns it with its secret
+func CreateApiKey(
+	ctx context.Context,
+	ps types.PersistenceService,
+	generateID func() (int64, error),
+	name string,
+	scopes []string,
+) (types.ApiKey, string, error) {
+	scopes, err := ValidateApiKey(name, scopes)
+	if err != nil {
+		return types.ApiKey{}, "", err
+	}
+
+	id, err := generateID()
+	if err != nil {
+		return types.ApiKey{}, "", err
+	}
+
+	secret, hash := NewApiKey()
+	key, err := ps.ApiKeyCreate(ctx, id, name, hash, scopes)
+	if err != nil {
+		return types.ApiKey{}, "", err
+	}
+	return key, secret, nil
+}
+
+// RevokeApiKey revokes the active API key id and returns it before and after revocation
+func RevokeApiKey(ctx context.Context, ps types.PersistenceService, id int64) (types.ApiKey, types.ApiKey, error) {
+	before, found, err := ps.ApiKeyLookupByID(ctx, id)
+	if err != nil {
+		return types.ApiKey{}, types.ApiKey{}, err
+	}
+	if !found || before.RevokedAt != 0 {
+		return types.ApiKey{}, types.ApiKey{}, ErrApiKeyNotFound
+	}
+
+	revoked, err := ps.ApiKeyRevoke(ctx, id)
+	if err != nil {
+		return types.ApiKey{}, types.ApiKey{}, err
+	}
+	if !revoked {
+		return types.ApiKey{}, types.ApiKey{}, ErrApiKeyNotFound
+	}
+
+	after, _, err := ps.ApiKeyLookupByID(ctx, id)
+	if err != nil {
+		return types.ApiKey{}, types.ApiKey{}, err
+	}
+	return before, after, nil
+}
+
+// RotateApiKey creates a key with the name and scopes of the active API key id, then
+// revokes id. It returns the old key after revocation and the new key with its secret.
+// If only the revocation fails, the new key is returned together with the error.
+func RotateApiKey(
+	ctx context.Context,
+	ps types.PersistenceService,
+	generateID func() (int64, error),
+	id int64,
+) (types.ApiKey, types.ApiKey, string, error) {
+	old, found, err := ps.ApiKeyLookupByID(ctx, id)
+	if err != nil {
+		return types.ApiKey{}, types.ApiKey{}, "", err
+	}
+	if !found || old.RevokedAt != 0 {
+		return types.ApiKey{}, types.ApiKey{}, "", ErrApiKeyNotFound
+	}
+
+	key, secret, err := CreateApiKey(ctx, ps, generateID, old.Name, old.Scopes)
+	if err != nil {
+		return types.ApiKey{}, types.ApiKey{}, "", err
+	}
+
+	_, revoked, err := RevokeApiKey(ctx, ps, id)
+	if err != nil {
+		// the new key is valid, so hand it out together with the error
+		return old, key, secret, fmt.Errorf("revoke rotated api key %d: %w", id, err)
+	}
+	return revoked, key, secret, nil
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+var ErrInvalidAuditCursor = errors.New("core: invalid audit cursor")
+
+// RecordAudit appends an action of actorKeyID on target to the audit log, storing
+// before and after as JSON. Maintenance commands and workers record as
+// types.AuditActorMaintenance.
+func RecordAudit(
+	ctx context.Context,
+	ps types.PersistenceService,
+	generateID func() (int64, error),
+	actorKeyID int64,
+	action string,
+	target string,
+	before any,
+	after any,
+) error {
+	id, err := generateID()
+	if err != nil {
+		return err
+	}
+
+	event := types.AuditEvent{
+		ID:         id,
+		ActorKeyID: actorKeyID,
+		Action:     action,
+		Target:     target,
+		CreatedAt:  time.Now().UnixNano(),
+	}
+	if event.Before, err = json.Marshal(before); err != nil {
+		return err
+	}
+	if event.After, err = json.Marshal(after); err != nil {
+		return err
+	}
+	return ps.AuditRecord(ctx, event)
+}
+
+// ApiKeyAuditTarget is the audit target of an API key
+func ApiKeyAuditTarget(id int64) string {
+	return fmt.Sprintf("api_key:%d", id)
+}
+
+// EncodeAuditCursor returns an opaque cursor continuing a listing after event
+func EncodeAuditCursor(event types.AuditEvent) string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d.%d", event.CreatedAt, event.ID))
+}
+
+// DecodeAuditCursor sets the cursor position of filter from a cursor returned by EncodeAuditCursor
+func DecodeAuditCursor(cursor string, filter *types.AuditFilter) error {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ErrInvalidAuditCursor
+	}
+	var createdAt, id int64
+	if n, err := fmt.Sscanf(string(raw), "%d.%d", &createdAt, &id); err != nil || n != 2 {
+		return ErrInvalidAuditCursor
+	}
+	filter.CursorCreatedAt, filter.CursorID = createdAt, id
+	return nil
+}
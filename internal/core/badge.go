@@ -0,0 +1,247 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const _BADGE_MAX_LABEL_LEN = 32
+
+var (
+	ErrInvalidBadgeColor = errors.New("core: invalid badge color")
+	ErrInvalidBadgeStyle = errors.New("core: invalid badge style")
+	ErrBadgeLabelTooLong = errors.New("core: badge label is too long")
+
+	ErrInvalidSparklineSize = errors.New("core: invalid sparkline size")
+)
+
+var _badge_hex_color_pattern = regexp.MustCompile(`^#?([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// named colors accepted in addition to hex colors, following shields.io
+var _badge_colors = map[string]string{
+	"brightgreen": "#4c1",
+	"green":       "#97ca00",
+	"yellow":      "#dfb317",
+	"yellowgreen": "#a4a61d",
+	"orange":      "#fe7d37",
+	"red":         "#e05d44",
+	"blue":        "#007ec6",
+	"grey":        "#555",
+	"gray":        "#555",
+	"lightgrey":   "#9f9f9f",
+	"lightgray":   "#9f9f9f",
+}
+
+// BadgeOptions controls the look of a count badge
+type BadgeOptions struct {
+	Label      string
+	LabelColor string // hex ("4c1", "#4c1") or named color
+	Color      string // hex or named color of the count
+	Style      string // "flat", "flat-square" or "plastic"
+}
+
+// Validate checks the options and resolves colors to hex, filling in defaults for empty fields
+func (o *BadgeOptions) Validate(defaultLabel string) error {
+	if o.Label == "" {
+		o.Label = defaultLabel
+	}
+	if utf8.RuneCountInString(o.Label) > _BADGE_MAX_LABEL_LEN {
+		return ErrBadgeLabelTooLong
+	}
+
+	var err error
+	if o.LabelColor, err = badgeColor(o.LabelColor, "#555"); err != nil {
+		return err
+	}
+	if o.Color, err = badgeColor(o.Color, "#007ec6"); err != nil {
+		return err
+	}
+
+	switch o.Style {
+	case "":
+		o.Style = "flat"
+	case "flat", "flat-square", "plastic":
+	default:
+		return ErrInvalidBadgeStyle
+	}
+	return nil
+}
+
+func badgeColor(c string, def string) (string, error) {
+	if c == "" {
+		return def, nil
+	}
+	if named, ok := _badge_colors[strings.ToLower(c)]; ok {
+		return named, nil
+	}
+	if _badge_hex_color_pattern.MatchString(c) {
+		return "#" + strings.TrimPrefix(c, "#"), nil
+	}
+	return "", ErrInvalidBadgeColor
+}
+
+// FormatCount formats a count for a badge, e.g. 999, 1.2k, 12k or 3.4M
+func FormatCount(n int64) string {
+	switch {
+	case n < 1000:
+		return fmt.Sprint(n)
+	case n < 10_000:
+		return strings.Replace(fmt.Sprintf("%.1fk", float64(n/100)/10), ".0k", "k", 1)
+	case n < 1_000_000:
+		return fmt.Sprintf("%dk", n/1000)
+	case n < 10_000_000:
+		return strings.Replace(fmt.Sprintf("%.1fM", float64(n/100_000)/10), ".0M", "M", 1)
+	}
+	return fmt.Sprintf("%dM", n/1_000_000)
+}
+
+// badgeTextWidth approximates the width of s in 11px Verdana
+func badgeTextWidth(s string) int {
+	width := 0.0
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune("iljI.,:;'|!", r):
+			width += 3.5
+		case strings.ContainsRune("frt ()[]", r):
+			width += 4.5
+		case strings.ContainsRune("mwMW", r):
+			width += 10.5
+		case r >= 'A' && r <= 'Z':
+			width += 7.5
+		case r > 0x2e80:
+			width += 11 // CJK
+		default:
+			width += 6.8
+		}
+	}
+	return int(width + 0.5)
+}
+
+// RenderBadge renders a shields.io style SVG badge showing message next to the label of opts,
+// which must have been validated.
+func RenderBadge(message string, opts BadgeOptions) []byte {
+	labelWidth := badgeTextWidth(opts.Label) + 10
+	messageWidth := badgeTextWidth(message) + 10
+	width := labelWidth + messageWidth
+
+	label := html.EscapeString(opts.Label)
+	message = html.EscapeString(message)
+
+	height, radius, textY := 20, 3, 14
+	gradient := `<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`
+	switch opts.Style {
+	case "flat-square":
+		radius = 0
+		gradient = ""
+	case "plastic":
+		height, radius, textY = 18, 4, 13
+		gradient = `<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#fff" stop-opacity=".7"/><stop offset=".1" stop-color="#aaa" stop-opacity=".1"/><stop offset=".9" stop-opacity=".3"/><stop offset="1" stop-opacity=".5"/></linearGradient>`
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" role="img" aria-label="%s: %s">`, width, height, label, message)
+	fmt.Fprintf(&b, `<title>%s: %s</title>`, label, message)
+	b.WriteString(gradient)
+	fmt.Fprintf(&b, `<clipPath id="r"><rect width="%d" height="%d" rx="%d" fill="#fff"/></clipPath>`, width, height, radius)
+	fmt.Fprintf(&b, `<g clip-path="url(#r)"><rect width="%d" height="%d" fill="%s"/><rect x="%d" width="%d" height="%d" fill="%s"/>`,
+		labelWidth, height, opts.LabelColor, labelWidth, messageWidth, height, opts.Color)
+	if gradient != "" {
+		fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="url(#s)"/>`, width, height)
+	}
+	b.WriteString(`</g><g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	for _, t := range []struct {
+		x    int
+		text string
+	}{{labelWidth / 2, label}, {labelWidth + messageWidth/2, message}} {
+		if gradient != "" {
+			fmt.Fprintf(&b, `<text x="%d" y="%d" fill="#010101" fill-opacity=".3">%s</text>`, t.x, textY+1, t.text)
+		}
+		fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`, t.x, textY, t.text)
+	}
+	b.WriteString(`</g></svg>`)
+	return []byte(b.String())
+}
+
+// SparklineOptions controls the look of a sparkline
+type SparklineOptions struct {
+	Width       int
+	Height      int
+	Color       string // hex or named color of the line
+	Fill        string // hex or named color of the area below the line, "none" for no area
+	StrokeWidth int
+}
+
+// Validate checks the options and resolves colors to hex, filling in defaults for empty fields
+func (o *SparklineOptions) Validate() error {
+	if o.Width == 0 {
+		o.Width = 120
+	}
+	if o.Height == 0 {
+		o.Height = 20
+	}
+	if o.StrokeWidth == 0 {
+		o.StrokeWidth = 1
+	}
+	if o.Width < 20 || o.Width > 1000 || o.Height < 10 || o.Height > 200 || o.StrokeWidth < 1 || o.StrokeWidth > 5 {
+		return ErrInvalidSparklineSize
+	}
+
+	var err error
+	if o.Color, err = badgeColor(o.Color, "#007ec6"); err != nil {
+		return err
+	}
+	if o.Fill != "none" {
+		if o.Fill, err = badgeColor(o.Fill, "#cce5f4"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenderSparkline renders values as an SVG line chart scaled to the largest value.
+// opts must have been validated.
+func RenderSparkline(values []int64, opts SparklineOptions) []byte {
+	var peak, total int64
+	for _, v := range values {
+		peak = max(peak, v)
+		total += v
+	}
+
+	// Keep the line inside the canvas including its stroke
+	pad := float64(opts.StrokeWidth) / 2
+	w := float64(opts.Width) - 2*pad
+	h := float64(opts.Height) - 2*pad
+
+	var points strings.Builder
+	for i, v := range values {
+		x := pad
+		if len(values) > 1 {
+			x += w * float64(i) / float64(len(values)-1)
+		}
+		y := pad + h
+		if peak > 0 {
+			y -= h * float64(v) / float64(peak)
+		}
+		if i > 0 {
+			points.WriteByte(' ')
+		}
+		fmt.Fprintf(&points, "%.1f,%.1f", x, y)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-label="%d views in %d days">`,
+		opts.Width, opts.Height, opts.Width, opts.Height, total, len(values))
+	fmt.Fprintf(&b, `<title>%d views in %d days</title>`, total, len(values))
+	if opts.Fill != "none" && len(values) > 1 {
+		fmt.Fprintf(&b, `<polygon points="%.1f,%.1f %s %.1f,%.1f" fill="%s" stroke="none"/>`,
+			pad, pad+h, points.String(), pad+w, pad+h, opts.Fill)
+	}
+	fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%d" stroke-linejoin="round" stroke-linecap="round"/>`,
+		points.String(), opts.Color, opts.StrokeWidth)
+	b.WriteString(`</svg>`)
+	return []byte(b.String())
+}
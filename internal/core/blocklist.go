@@ -0,0 +1,120 @@
+package core
+
+import (
+	"errors"
+	"net/netip"
+	"regexp"
+	"strings"
+
+	"gosuda.org/randflake"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+const _BLOCK_VALUE_MAX_LENGTH = 255
+
+var ErrInvalidBlockValue = errors.New("core: invalid blocklist value")
+
+// NormalizeBlockValue validates the value of a blocklist entry and returns it in
+// canonical form: networks are masked and single addresses become /32 or /128.
+func NormalizeBlockValue(kind types.BlockKind, value string) (string, error) {
+	if kind.String() == "" {
+		return "", types.ErrInvalidBlockKind
+	}
+	value = strings.TrimSpace(value)
+	if value == "" || len(value) > _BLOCK_VALUE_MAX_LENGTH {
+		return "", ErrInvalidBlockValue
+	}
+
+	switch kind {
+	case types.BlockClient:
+		if _, err := randflake.DecodeString(value); err != nil {
+			return "", ErrInvalidBlockValue
+		}
+	case types.BlockNetwork:
+		if addr, err := netip.ParseAddr(value); err == nil {
+			return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()).String(), nil
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return "", ErrInvalidBlockValue
+		}
+		return prefix.Masked().String(), nil
+	case types.BlockUserAgent:
+		if _, err := regexp.Compile("(?i)" + value); err != nil {
+			return "", ErrInvalidBlockValue
+		}
+	}
+	return value, nil
+}
+
+// Blocklist matches client submissions against the active blocklist entries.
+// It is immutable and safe for concurrent use; a nil Blocklist blocks nothing.
+type Blocklist struct {
+	clients    map[int64]struct{}
+	networks   []netip.Prefix
+	userAgents []*regexp.Regexp
+
+	fingerprints map[string]struct{}
+}
+
+// NewBlocklist compiles entries. fingerprintClients are the clients that checked in
+// with a blocked fingerprint. Invalid entries are skipped.
+func NewBlocklist(entries []types.BlockEntry, fingerprintClients []int64) *Blocklist {
+	b := &Blocklist{
+		clients:      make(map[int64]struct{}, len(fingerprintClients)),
+		fingerprints: make(map[string]struct{}),
+	}
+	for _, id := range fingerprintClients {
+		b.clients[id] = struct{}{}
+	}
+
+	for _, e := range entries {
+		switch e.Kind {
+		case types.BlockClient:
+			if id, err := randflake.DecodeString(e.Value); err == nil {
+				b.clients[id] = struct{}{}
+			}
+		case types.BlockFingerprint:
+			b.fingerprints[e.Value] = struct{}{}
+		case types.BlockNetwork:
+			if prefix, err := netip.ParsePrefix(e.Value); err == nil {
+				b.networks = append(b.networks, prefix)
+			}
+		case types.BlockUserAgent:
+			if re, err := regexp.Compile("(?i)" + e.Value); err == nil {
+				b.userAgents = append(b.userAgents, re)
+			}
+		}
+	}
+	return b
+}
+
+// Blocked reports whether a submission described by c is blocked
+func (b *Blocklist) Blocked(c types.BlockCheck) bool {
+	if b == nil {
+		return false
+	}
+
+	if _, ok := b.clients[c.ClientID]; ok && c.ClientID != 0 {
+		return true
+	}
+	if _, ok := b.fingerprints[c.Fingerprint]; ok && c.Fingerprint != "" {
+		return true
+	}
+	if len(b.networks) > 0 {
+		if addr, err := netip.ParseAddr(c.IP); err == nil {
+			addr = addr.Unmap()
+			for _, prefix := range b.networks {
+				if prefix.Contains(addr) {
+					return true
+				}
+			}
+		}
+	}
+	for _, re := range b.userAgents {
+		if re.MatchString(c.UserAgent) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"gosuda.org/randflake"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+func TestNormalizeBlockValue(t *testing.T) {
+	client := randflake.EncodeString(123456789)
+
+	tests := []struct {
+		name  string
+		kind  types.BlockKind
+		value string
+		want  string
+		err   error
+	}{
+		{"client id", types.BlockClient, client, client, nil},
+		{"client id with spaces", types.BlockClient, " " + client + " ", client, nil},
+		{"invalid client id", types.BlockClient, "not-an-id!", "", ErrInvalidBlockValue},
+		{"fingerprint", types.BlockFingerprint, "abc123", "abc123", nil},
+		{"ipv4 address", types.BlockNetwork, "192.0.2.7", "192.0.2.7/32", nil},
+		{"ipv6 address", types.BlockNetwork, "2001:db8::1", "2001:db8::1/128", nil},
+		{"ipv4-mapped address", types.BlockNetwork, "::ffff:192.0.2.7", "192.0.2.7/32", nil},
+		{"network is masked", types.BlockNetwork, "192.0.2.77/24", "192.0.2.0/24", nil},
+		{"ipv6 network is masked", types.BlockNetwork, "2001:db8::1/32", "2001:db8::/32", nil},
+		{"invalid network", types.BlockNetwork, "192.0.2.0/33", "", ErrInvalidBlockValue},
+		{"invalid address", types.BlockNetwork, "example.com", "", ErrInvalidBlockValue},
+		{"user agent pattern", types.BlockUserAgent, `curl/\d+`, `curl/\d+`, nil},
+		{"invalid user agent pattern", types.BlockUserAgent, "curl(", "", ErrInvalidBlockValue},
+		{"empty value", types.BlockFingerprint, "  ", "", ErrInvalidBlockValue},
+		{"too long value", types.BlockFingerprint, string(make([]byte, _BLOCK_VALUE_MAX_LENGTH+1)), "", ErrInvalidBlockValue},
+		{"unknown kind", types.BlockKind(99), "x", "", types.ErrInvalidBlockKind},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeBlockValue(tt.kind, tt.value)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("NormalizeBlockValue(%v, %q) error = %v, want %v", tt.kind, tt.value, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeBlockValue(%v, %q) = %q, want %q", tt.kind, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlocklistBlocked(t *testing.T) {
+	const (
+		blockedClient     int64 = 1001
+		fingerprintClient int64 = 1002
+	)
+
+	b := NewBlocklist([]types.BlockEntry{
+		{Kind: types.BlockClient, Value: randflake.EncodeString(blockedClient)},
+		{Kind: types.BlockClient, Value: "not-an-id!"},
+		{Kind: types.BlockFingerprint, Value: "bad-fingerprint"},
+		{Kind: types.BlockNetwork, Value: "192.0.2.0/24"},
+		{Kind: types.BlockNetwork, Value: "2001:db8::/32"},
+		{Kind: types.BlockNetwork, Value: "invalid"},
+		{Kind: types.BlockUserAgent, Value: `curl/\d+`},
+		{Kind: types.BlockUserAgent, Value: "invalid("},
+	}, []int64{fingerprintClient})
+
+	tests := []struct {
+		name  string
+		check types.BlockCheck
+		want  bool
+	}{
+		{"allowed", types.BlockCheck{ClientID: 1, IP: "198.51.100.1", UserAgent: "Mozilla/5.0"}, false},
+		{"empty check", types.BlockCheck{}, false},
+		{"blocked client", types.BlockCheck{ClientID: blockedClient}, true},
+		{"client of blocked fingerprint", types.BlockCheck{ClientID: fingerprintClient}, true},
+		{"blocked fingerprint", types.BlockCheck{ClientID: 1, Fingerprint: "bad-fingerprint"}, true},
+		{"other fingerprint", types.BlockCheck{ClientID: 1, Fingerprint: "good-fingerprint"}, false},
+		{"ipv4 in network", types.BlockCheck{IP: "192.0.2.200"}, true},
+		{"ipv4-mapped in network", types.BlockCheck{IP: "::ffff:192.0.2.200"}, true},
+		{"ipv4 outside network", types.BlockCheck{IP: "192.0.3.1"}, false},
+		{"ipv6 in network", types.BlockCheck{IP: "2001:db8:1::5"}, true},
+		{"invalid ip", types.BlockCheck{IP: "unknown"}, false},
+		{"user agent matches case-insensitively", types.BlockCheck{UserAgent: "CURL/8.4.0"}, true},
+		{"user agent without match", types.BlockCheck{UserAgent: "curl"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.Blocked(tt.check); got != tt.want {
+				t.Errorf("Blocked(%+v) = %v, want %v", tt.check, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNilBlocklistBlocksNothing(t *testing.T) {
+	var b *Blocklist
+	if b.Blocked(types.BlockCheck{ClientID: 1, IP: "192.0.2.1", UserAgent: "curl/8"}) {
+		t.Error("nil Blocklist blocked a submission")
+	}
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"net/url"
+	"strings"
+
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+const _MAX_CAMPAIGN_PARAM_LENGTH = 255
+
+// ExtractCampaign extracts the UTM parameters from a raw URL. It must be
+// called before NormalizeURL since normalization drops the query string.
+// Unparsable URLs yield an empty campaign.
+func ExtractCampaign(raw string) types.Campaign {
+	u, err := url.Parse(raw)
+	if err != nil {
+		u, err = url.Parse("http://" + raw)
+		if err != nil {
+			return types.Campaign{}
+		}
+	}
+
+	q := u.Query()
+	return types.Campaign{
+		Source:  campaignParam(q, "utm_source"),
+		Medium:  campaignParam(q, "utm_medium"),
+		Name:    campaignParam(q, "utm_campaign"),
+		Term:    campaignParam(q, "utm_term"),
+		Content: campaignParam(q, "utm_content"),
+	}
+}
+
+func campaignParam(q url.Values, key string) string {
+	v := strings.TrimSpace(q.Get(key))
+	if len(v) > _MAX_CAMPAIGN_PARAM_LENGTH {
+		v = strings.ToValidUTF8(v[:_MAX_CAMPAIGN_PARAM_LENGTH], "")
+	}
+	return v
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"crypto/sha256"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+const (
+	_ERROR_MAX_MESSAGE_LEN    = 1024
+	_ERROR_MAX_STACK_LEN      = 16 << 10
+	_ERROR_MAX_SOURCE_LEN     = 1024
+	_ERROR_MAX_USER_AGENT_LEN = 512
+	_ERROR_FINGERPRINT_FRAMES = 10
+)
+
+var ErrEmptyErrorMessage = errors.New("core: error message is required")
+
+var (
+	// Chrome: "    at fn (https://example.com/app.js?v=1:10:20)" or "    at https://example.com/app.js:10:20"
+	_chrome_frame_pattern = regexp.MustCompile(`^\s*at (?:(.+?) \()?(.+?)(?::\d+)?(?::\d+)?\)?$`)
+	// Firefox and Safari: "fn@https://example.com/app.js:10:20"
+	_gecko_frame_pattern = regexp.MustCompile(`^\s*(.*?)@(.+?)(?::\d+)?(?::\d+)?$`)
+	_digits_pattern      = regexp.MustCompile(`[0-9]+`)
+)
+
+// NewErrorReport validates and truncates a client error report and computes its fingerprint.
+// pageURL must already be normalized.
+func NewErrorReport(message, stack, source string, line, column int32, userAgent string, pageURL string) (types.ErrorReport, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return types.ErrorReport{}, ErrEmptyErrorMessage
+	}
+
+	report := types.ErrorReport{
+		Message:   truncateString(message, _ERROR_MAX_MESSAGE_LEN),
+		Stack:     truncateString(stack, _ERROR_MAX_STACK_LEN),
+		Source:    truncateString(source, _ERROR_MAX_SOURCE_LEN),
+		Line:      max(line, 0),
+		Column:    max(column, 0),
+		UserAgent: truncateString(userAgent, _ERROR_MAX_USER_AGENT_LEN),
+		URL:       pageURL,
+	}
+	report.Fingerprint = ErrorFingerprint(report.Message, report.Stack, report.Source)
+	return report, nil
+}
+
+// ErrorFingerprint groups reports of the same error. It hashes the message with numbers
+// masked and the function names and script paths of the top stack frames, ignoring line
+// and column numbers and query strings, which change between builds and browsers.
+// Without a parsable stack the script path is used instead.
+func ErrorFingerprint(message, stack, source string) []byte {
+	var b strings.Builder
+	b.WriteString(_digits_pattern.ReplaceAllString(message, "0"))
+
+	frames := NormalizeStack(stack)
+	if len(frames) == 0 {
+		frames = []string{scriptPath(source)}
+	}
+	for _, frame := range frames {
+		b.WriteByte('\n')
+		b.WriteString(frame)
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return sum[:]
+}
+
+// NormalizeStack returns up to 10 stack frames as "function script-path",
+// accepting both the V8 and the Firefox/Safari stack formats.
+func NormalizeStack(stack string) []string {
+	frames := make([]string, 0, _ERROR_FINGERPRINT_FRAMES)
+	for _, line := range strings.Split(stack, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var m []string
+		if strings.HasPrefix(line, "at ") {
+			m = _chrome_frame_pattern.FindStringSubmatch(line)
+		} else if strings.Contains(line, "@") {
+			m = _gecko_frame_pattern.FindStringSubmatch(line)
+		}
+		if m == nil {
+			// the message line V8 puts in front of the frames
+			continue
+		}
+
+		frames = append(frames, m[1]+" "+scriptPath(m[2]))
+		if len(frames) == _ERROR_FINGERPRINT_FRAMES {
+			break
+		}
+	}
+	return frames
+}
+
+// scriptPath strips the query string and fragment of a script URL
+func scriptPath(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// truncateString cuts s to at most n bytes without splitting a UTF-8 sequence
+func truncateString(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeStack(t *testing.T) {
+	tests := []struct {
+		name  string
+		stack string
+		want  []string
+	}{
+		{
+			"v8",
+			"TypeError: x is undefined\n    at render (https://example.com/app.js?v=3:10:20)\n    at https://example.com/vendor.js:1:2",
+			[]string{"render https://example.com/app.js", " https://example.com/vendor.js"},
+		},
+		{
+			"gecko",
+			"render@https://example.com/app.js?v=3:10:20\n@https://example.com/vendor.js:1:2\n",
+			[]string{"render https://example.com/app.js", " https://example.com/vendor.js"},
+		},
+		{"no frames", "TypeError: x is undefined", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeStack(tt.stack); !slices.Equal(got, tt.want) {
+				t.Errorf("NormalizeStack() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	long := strings.Repeat("    at f (https://example.com/app.js:1:1)\n", 20)
+	if got := len(NormalizeStack(long)); got != _ERROR_FINGERPRINT_FRAMES {
+		t.Errorf("NormalizeStack() returned %d frames, want %d", got, _ERROR_FINGERPRINT_FRAMES)
+	}
+}
+
+func TestErrorFingerprint(t *testing.T) {
+	base := ErrorFingerprint("Cannot read 'x' of item 12", "    at render (https://example.com/app.js?v=1:10:20)", "")
+
+	same := []struct {
+		name                   string
+		message, stack, source string
+	}{
+		{"other numbers", "Cannot read 'x' of item 345", "    at render (https://example.com/app.js?v=1:10:20)", ""},
+		{"other build", "Cannot read 'x' of item 12", "    at render (https://example.com/app.js?v=2:11:4)", ""},
+		{"gecko format", "Cannot read 'x' of item 12", "render@https://example.com/app.js:10:20", ""},
+	}
+	for _, tt := range same {
+		if got := ErrorFingerprint(tt.message, tt.stack, tt.source); !bytes.Equal(got, base) {
+			t.Errorf("%s: fingerprint differs", tt.name)
+		}
+	}
+
+	different := []struct {
+		name                   string
+		message, stack, source string
+	}{
+		{"other message", "Cannot read 'y' of item 12", "    at render (https://example.com/app.js:10:20)", ""},
+		{"other function", "Cannot read 'x' of item 12", "    at update (https://example.com/app.js:10:20)", ""},
+		{"other script", "Cannot read 'x' of item 12", "    at render (https://example.com/other.js:10:20)", ""},
+	}
+	for _, tt := range different {
+		if got := ErrorFingerprint(tt.message, tt.stack, tt.source); bytes.Equal(got, base) {
+			t.Errorf("%s: fingerprint equals base", tt.name)
+		}
+	}
+
+	a := ErrorFingerprint("Script error", "", "https://example.com/app.js?v=1")
+	b := ErrorFingerprint("Script error", "", "https://example.com/app.js?v=2")
+	if !bytes.Equal(a, b) {
+		t.Error("source fallback fingerprint depends on the query string")
+	}
+}
+
+func TestNewErrorReport(t *testing.T) {
+	if _, err := NewErrorReport("  ", "", "", 0, 0, "", "example.com/"); !errors.Is(err, ErrEmptyErrorMessage) {
+		t.Errorf("NewErrorReport with a blank message error = %v, want %v", err, ErrEmptyErrorMessage)
+	}
+
+	report, err := NewErrorReport(strings.Repeat("é", _ERROR_MAX_MESSAGE_LEN), "", "", -1, -2, "", "example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Message) > _ERROR_MAX_MESSAGE_LEN || !strings.HasSuffix(report.Message, "é") {
+		t.Errorf("message truncated to %d bytes, splitting a rune", len(report.Message))
+	}
+	if report.Line != 0 || report.Column != 0 {
+		t.Errorf("line and column = %d, %d, want 0, 0", report.Line, report.Column)
+	}
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// _EVENT_MAX_AGE bounds how old queued events may be. It must not exceed one day
+	// so that late events never fall into a day already rolled up by the retention worker.
+	_EVENT_MAX_AGE            = time.Hour * 24
+	_EVENT_MAX_CLOCK_SKEW     = time.Minute * 5
+	_EVENT_MAX_PROPERTIES_LEN = 1024
+	_EVENT_MAX_PROPERTIES     = 10
+	_EVENT_MAX_PROPERTY_VALUE = 128 // characters
+
+	// EventMaxNamesPerSite caps the number of distinct custom event names of a site
+	EventMaxNamesPerSite = 200
+	// EventMaxNamesPerClient caps the number of event names a single client may introduce,
+	// since the site of an event is taken from a URL the client supplies
+	EventMaxNamesPerClient = 20
+)
+
+var (
+	ErrEventTooOld            = errors.New("core: event timestamp is too old")
+	ErrEventInFuture          = errors.New("core: event timestamp is in the future")
+	ErrInvalidEventName       = errors.New("core: invalid event name")
+	ErrInvalidEventProperties = errors.New("core: event properties must be a JSON object of strings")
+	ErrEventPropertiesTooLong = errors.New("core: event properties are too long")
+	ErrTooManyEventProperties = errors.New("core: too many event properties")
+)
+
+var (
+	_event_name_pattern     = regexp.MustCompile(`^[A-Za-z0-9_.:-]{1,64}$`)
+	_event_property_pattern = regexp.MustCompile(`^[A-Za-z0-9_.:-]{1,32}$`)
+)
+
+// EventTime validates a client timestamp in unix milliseconds against now and
+// returns it in unix nanoseconds. A zero timestamp means now.
+func EventTime(ms int64, now time.Time) (int64, error) {
+	if ms == 0 {
+		return now.UnixNano(), nil
+	}
+
+	ts := time.UnixMilli(ms)
+	if ts.Before(now.Add(-_EVENT_MAX_AGE)) {
+		return 0, ErrEventTooOld
+	}
+	if ts.After(now.Add(_EVENT_MAX_CLOCK_SKEW)) {
+		return 0, ErrEventInFuture
+	}
+	return ts.UnixNano(), nil
+}
+
+// ValidateEventName checks that a custom event name is 1-64 characters of
+// letters, digits, '_', '.', ':' and '-'.
+func ValidateEventName(name string) error {
+	if !_event_name_pattern.MatchString(name) {
+		return ErrInvalidEventName
+	}
+	return nil
+}
+
+// EventProperties validates raw custom event properties and returns them in compact
+// form. Properties are at most 10 string values with names like event names of up to
+// 32 characters and values of up to 128 characters. Missing properties are stored as
+// an empty object.
+func EventProperties(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "{}", nil
+	}
+
+	var props map[string]string
+	if err := json.Unmarshal(raw, &props); err != nil {
+		return "", ErrInvalidEventProperties
+	}
+	if len(props) > _EVENT_MAX_PROPERTIES {
+		return "", ErrTooManyEventProperties
+	}
+	for key, value := range props {
+		if !_event_property_pattern.MatchString(key) {
+			return "", ErrInvalidEventProperties
+		}
+		if utf8.RuneCountInString(value) > _EVENT_MAX_PROPERTY_VALUE {
+			return "", ErrEventPropertiesTooLong
+		}
+	}
+
+	compact, err := json.Marshal(props)
+	if err != nil {
+		return "", ErrInvalidEventProperties
+	}
+	if len(compact) > _EVENT_MAX_PROPERTIES_LEN {
+		return "", ErrEventPropertiesTooLong
+	}
+	return string(compact), nil
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+const (
+	_TRENDING_HALF_LIFE   = 24 * time.Hour
+	_TRENDING_HORIZON     = 7 * 24 * time.Hour // older activity weighs less than 1% and is ignored
+	_TRENDING_LIKE_WEIGHT = 5
+
+	// Leaderboards keep this many URLs per site, the most /top returns for a site or for all sites
+	_RANKING_MAX_PER_SITE = 100
+)
+
+var (
+	ErrInvalidRankingMetric = errors.New("core: invalid ranking metric")
+	ErrInvalidRankingWindow = errors.New("core: invalid ranking window")
+)
+
+var _ranking_metrics = map[string]int32{
+	"views":    types.RankingViews,
+	"likes":    types.RankingLikes,
+	"trending": types.RankingTrending,
+}
+
+var _ranking_windows = map[string]int32{
+	"all": types.RankingAllTime,
+	"24h": types.Ranking24h,
+	"7d":  types.Ranking7d,
+	"30d": types.Ranking30d,
+}
+
+var _ranking_window_durations = map[int32]time.Duration{
+	types.Ranking24h: 24 * time.Hour,
+	types.Ranking7d:  7 * 24 * time.Hour,
+	types.Ranking30d: 30 * 24 * time.Hour,
+}
+
+// RankingMetric returns the code of a leaderboard metric name
+func RankingMetric(name string) (int32, error) {
+	metric, ok := _ranking_metrics[name]
+	if !ok {
+		return 0, ErrInvalidRankingMetric
+	}
+	return metric, nil
+}
+
+// RankingWindow returns the code of a leaderboard window name
+func RankingWindow(name string) (int32, error) {
+	window, ok := _ranking_windows[name]
+	if !ok {
+		return 0, ErrInvalidRankingWindow
+	}
+	return window, nil
+}
+
+// RebuildRankings recomputes all leaderboards: view and like counts per window from
+// the raw rows and rollups (all time from the counters), and the trending score, the sum
+// of views and likes weighted by _TRENDING_LIKE_WEIGHT, each decaying with a half-life of
+// _TRENDING_HALF_LIFE. Only the top _RANKING_MAX_PER_SITE URLs of every site are stored.
+// It returns the number of URLs stored over all leaderboards.
+func RebuildRankings(ctx context.Context, ps types.PersistenceService, now time.Time) (int, error) {
+	nowNs := now.UnixNano()
+	ranked := 0
+
+	for _, metric := range []int32{types.RankingViews, types.RankingLikes} {
+		for _, window := range []int32{types.RankingAllTime, types.Ranking24h, types.Ranking7d, types.Ranking30d} {
+			var from int64
+			if d, ok := _ranking_window_durations[window]; ok {
+				from = nowNs - int64(d)
+			}
+			scores, err := ps.RankingCounts(ctx, metric, from)
+			if err != nil {
+				return ranked, err
+			}
+			scores = topPerSite(scores)
+			if err := ps.RankingReplace(ctx, metric, window, scores, nowNs); err != nil {
+				return ranked, err
+			}
+			ranked += len(scores)
+		}
+	}
+
+	tau := int64(math.Round(float64(_TRENDING_HALF_LIFE) / math.Ln2))
+	from := nowNs - int64(_TRENDING_HORIZON)
+	trending := map[int64]types.RankingScore{}
+	for metric, weight := range map[int32]float64{types.RankingViews: 1, types.RankingLikes: _TRENDING_LIKE_WEIGHT} {
+		scores, err := ps.RankingDecayed(ctx, metric, nowNs, tau, from)
+		if err != nil {
+			return ranked, err
+		}
+		for _, s := range scores {
+			t := trending[s.UrlID]
+			trending[s.UrlID] = types.RankingScore{UrlID: s.UrlID, URL: s.URL, Score: t.Score + s.Score*weight}
+		}
+	}
+	scores := make([]types.RankingScore, 0, len(trending))
+	for _, s := range trending {
+		scores = append(scores, s)
+	}
+	scores = topPerSite(scores)
+	if err := ps.RankingReplace(ctx, types.RankingTrending, types.RankingAllTime, scores, nowNs); err != nil {
+		return ranked, err
+	}
+	return ranked + len(scores), nil
+}
+
+// topPerSite returns the _RANKING_MAX_PER_SITE highest scores of every site, highest first.
+// The top of all sites is always among them. scores is reordered in place.
+func topPerSite(scores []types.RankingScore) []types.RankingScore {
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].UrlID < scores[j].UrlID
+	})
+
+	perSite := make(map[string]int)
+	out := scores[:0]
+	for _, s := range scores {
+		site := URLSite(s.URL)
+		if perSite[site] >= _RANKING_MAX_PER_SITE {
+			continue
+		}
+		perSite[site]++
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTooManyConnections = errors.New("core: too many connections")
+)
+
+// RateLimiter allows up to a fixed number of events per key in fixed time windows.
+// It is safe for concurrent use. State is kept in memory, so the limit applies per node.
+type RateLimiter struct {
+	mu          sync.Mutex
+	limit       int
+	window      time.Duration
+	windowStart time.Time
+	counts      map[int64]int
+}
+
+// NewRateLimiter creates a RateLimiter allowing limit events per key and window
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:  limit,
+		window: window,
+		counts: make(map[int64]int),
+	}
+}
+
+// Allow records an event of key at now and reports whether it is within the limit
+func (l *RateLimiter) Allow(key int64, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.windowStart) >= l.window {
+		// Dropping all counters at once bounds memory to the keys seen in one window
+		l.windowStart = now
+		clear(l.counts)
+	}
+	if l.counts[key] >= l.limit {
+		return false
+	}
+	l.counts[key]++
+	return true
+}
+
+// ConnLimiter limits the number of concurrent connections per key, e.g. per IP address.
+// It is safe for concurrent use.
+type ConnLimiter struct {
+	mu    sync.Mutex
+	limit int
+	conns map[string]int
+}
+
+// NewConnLimiter creates a ConnLimiter allowing limit concurrent connections per key
+func NewConnLimiter(limit int) *ConnLimiter {
+	return &ConnLimiter{
+		limit: limit,
+		conns: make(map[string]int),
+	}
+}
+
+// Acquire takes a connection slot of key. It returns ErrTooManyConnections if key has
+// no free slot; otherwise release must be called once the connection is closed.
+func (l *ConnLimiter) Acquire(key string) (release func(), err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conns[key] >= l.limit {
+		return nil, ErrTooManyConnections
+	}
+	l.conns[key]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			if l.conns[key]--; l.conns[key] <= 0 {
+				delete(l.conns, key)
+			}
+		})
+	}, nil
+}
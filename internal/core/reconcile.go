@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+const _RECONCILE_DEFAULT_BATCH_SIZE = 500
+
+// ReconcileOptions controls a count reconciliation run
+type ReconcileOptions struct {
+	BatchSize  int32         // URLs checked per query, defaults to 500
+	BatchDelay time.Duration // pause between batches to limit database load
+	Fix        bool          // rewrite drifted counters from the raw rows
+}
+
+// _RECONCILE_AUDIT_TARGET is the audit target of the summary event of a reconciliation run
+const _RECONCILE_AUDIT_TARGET = "counts"
+
+// reconcileAudit is the audit log state of a reconciliation run that fixed counters
+type reconcileAudit struct {
+	types.ReconcileResult
+	URLs []string `json:"urls"` // URLs whose counters were fixed
+}
+
+// ReconcileCounts walks all URLs in batches and compares their view and like
+// counters with the raw views and likes. Every drifted URL is passed to report.
+// With opts.Fix set, drifted counters are rewritten, and a run that fixed any
+// counters is recorded as one audit event, even if it fails part way; generateID
+// supplies IDs for count rows and the audit event.
+func ReconcileCounts(
+	ctx context.Context,
+	ps types.PersistenceService,
+	generateID func() (int64, error),
+	opts ReconcileOptions,
+	report func(types.CountCheck),
+) (types.ReconcileResult, error) {
+	var fixed []string
+	result, err := reconcileCounts(ctx, ps, generateID, opts, func(c types.CountCheck) {
+		if c.Fixed {
+			fixed = append(fixed, c.URL)
+		}
+		if report != nil {
+			report(c)
+		}
+	})
+	if len(fixed) == 0 {
+		return result, err
+	}
+
+	// the counters are already fixed, so the run is recorded even if it was cancelled
+	auditErr := RecordAudit(context.WithoutCancel(ctx), ps, generateID, types.AuditActorMaintenance,
+		types.AuditCountsFix, _RECONCILE_AUDIT_TARGET, nil, reconcileAudit{ReconcileResult: result, URLs: fixed})
+	return result, errors.Join(err, auditErr)
+}
+
+func reconcileCounts(
+	ctx context.Context,
+	ps types.PersistenceService,
+	generateID func() (int64, error),
+	opts ReconcileOptions,
+	report func(types.CountCheck),
+) (types.ReconcileResult, error) {
+	var result types.ReconcileResult
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = _RECONCILE_DEFAULT_BATCH_SIZE
+	}
+
+	var afterID int64
+	for {
+		checks, err := ps.CountReconcileBatch(ctx, afterID, batchSize)
+		if err != nil {
+			return result, err
+		}
+
+		for _, c := range checks {
+			result.Checked++
+			if !c.Drifted() {
+				continue
+			}
+			result.Discrepancies++
+
+			if opts.Fix {
+				c.Fixed, err = fixCounts(ctx, ps, generateID, c)
+				if err != nil {
+					return result, err
+				}
+				if c.Fixed {
+					result.Fixed++
+				}
+			}
+
+			report(c)
+		}
+
+		if len(checks) < int(batchSize) {
+			return result, nil
+		}
+		afterID = checks[len(checks)-1].UrlID
+
+		if opts.BatchDelay > 0 {
+			select {
+			case <-time.After(opts.BatchDelay):
+			case <-ctx.Done():
+				return result, ctx.Err()
+			}
+		}
+	}
+}
+
+func fixCounts(ctx context.Context, ps types.PersistenceService, generateID func() (int64, error), c types.CountCheck) (bool, error) {
+	fixed := true
+
+	if c.ViewCount != c.ViewRows {
+		id, err := generateID()
+		if err != nil {
+			return false, err
+		}
+		ok, err := ps.ViewCountReconcile(ctx, id, c.UrlID, c.ViewCount, c.ViewRows)
+		if err != nil {
+			return false, err
+		}
+		fixed = fixed && ok
+	}
+
+	if c.LikeCount != c.LikeRows {
+		id, err := generateID()
+		if err != nil {
+			return false, err
+		}
+		ok, err := ps.LikeCountReconcile(ctx, id, c.UrlID, c.LikeCount, c.LikeRows)
+		if err != nil {
+			return false, err
+		}
+		fixed = fixed && ok
+	}
+
+	return fixed, nil
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"strings"
+
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// _search_engines lists registrable domains of search engines. Hosts
+// matching "google." are handled separately to cover country domains.
+var _search_engines = []string{
+	"bing.com",
+	"duckduckgo.com",
+	"search.yahoo.com",
+	"yandex.ru",
+	"yandex.com",
+	"baidu.com",
+	"search.naver.com",
+	"search.daum.net",
+	"ecosia.org",
+	"search.brave.com",
+	"kagi.com",
+	"startpage.com",
+	"perplexity.ai",
+}
+
+var _social_networks = []string{
+	"facebook.com",
+	"fb.com",
+	"t.co",
+	"twitter.com",
+	"x.com",
+	"linkedin.com",
+	"lnkd.in",
+	"reddit.com",
+	"news.ycombinator.com",
+	"instagram.com",
+	"threads.net",
+	"youtube.com",
+	"bsky.app",
+	"mastodon.social",
+	"discord.com",
+	"t.me",
+	"kakao.com",
+	"band.us",
+	"lobste.rs",
+}
+
+// ParseReferrer normalizes a raw referrer into a source host and classifies it.
+// pageURL is the URL being viewed and is used to detect internal navigation.
+// Empty or unparsable referrers are treated as direct traffic.
+func ParseReferrer(raw string, pageURL string) types.Referrer {
+	source := referrerHost(raw)
+	if source == "" {
+		return types.Referrer{Class: types.ReferrerClassDirect}
+	}
+
+	if pageHost := referrerHost(pageURL); pageHost != "" && pageHost == source {
+		return types.Referrer{Source: source, Class: types.ReferrerClassInternal}
+	}
+
+	if strings.HasPrefix(source, "google.") || hostMatchesAny(source, _search_engines) {
+		return types.Referrer{Source: source, Class: types.ReferrerClassSearch}
+	}
+
+	if hostMatchesAny(source, _social_networks) {
+		return types.Referrer{Source: source, Class: types.ReferrerClassSocial}
+	}
+
+	return types.Referrer{Source: source, Class: types.ReferrerClassOther}
+}
+
+// referrerHost returns the lowercased host of a URL without port and common
+// "www." and "m." prefixes, using the same parsing rules as NormalizeURL.
+func referrerHost(raw string) string {
+	normalized, err := NormalizeURL(strings.TrimSpace(raw))
+	if err != nil {
+		return ""
+	}
+
+	host := normalized
+	if i := strings.Index(host, "/"); i != -1 {
+		host = host[:i]
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, prefix := range []string{"www.", "m.", "mobile."} {
+		host = strings.TrimPrefix(host, prefix)
+	}
+	if len(host) > 255 {
+		host = host[:255]
+	}
+	return host
+}
+
+// hostMatchesAny reports whether host equals or is a subdomain of any of the domains
+func hostMatchesAny(host string, domains []string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
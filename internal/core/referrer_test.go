@@ -0,0 +1,35 @@
+package core
+
+import (
+	"testing"
+
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+func TestParseReferrer(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		page string
+		want types.Referrer
+	}{
+		{"empty", "", "example.com/a", types.Referrer{Class: types.ReferrerClassDirect}},
+		{"blank", "   ", "example.com/a", types.Referrer{Class: types.ReferrerClassDirect}},
+		{"internal", "https://example.com/b", "example.com/a", types.Referrer{Source: "example.com", Class: types.ReferrerClassInternal}},
+		{"internal through www", "https://www.Example.com/b", "example.com/a", types.Referrer{Source: "example.com", Class: types.ReferrerClassInternal}},
+		{"google country domain", "https://www.google.co.kr/", "example.com/a", types.Referrer{Source: "google.co.kr", Class: types.ReferrerClassSearch}},
+		{"search subdomain", "https://search.naver.com/search.naver?query=x", "example.com/a", types.Referrer{Source: "search.naver.com", Class: types.ReferrerClassSearch}},
+		{"mobile social", "https://m.facebook.com/", "example.com/a", types.Referrer{Source: "facebook.com", Class: types.ReferrerClassSocial}},
+		{"social subdomain", "https://old.reddit.com/r/golang", "example.com/a", types.Referrer{Source: "old.reddit.com", Class: types.ReferrerClassSocial}},
+		{"lookalike is not social", "https://notreddit.com/", "example.com/a", types.Referrer{Source: "notreddit.com", Class: types.ReferrerClassOther}},
+		{"port and trailing dot", "https://blog.example.org.:8443/post", "example.com/a", types.Referrer{Source: "blog.example.org", Class: types.ReferrerClassOther}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseReferrer(tt.raw, tt.page); got != tt.want {
+				t.Errorf("ParseReferrer(%q, %q) = %+v, want %+v", tt.raw, tt.page, got, tt.want)
+			}
+		})
+	}
+}
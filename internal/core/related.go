@@ -0,0 +1,105 @@
+package core
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+const (
+	_RELATED_WINDOW      = 90 * 24 * time.Hour
+	_RELATED_MAX_PER_URL = 20
+
+	// Views are read for this many clients at a time, and only the most recently read URLs of
+	// a client are paired so that the work per client stays bounded.
+	_RELATED_CLIENT_BATCH        = 1000
+	_RELATED_MAX_URLS_PER_CLIENT = 100
+)
+
+// RelatedOptions controls a recommendation rebuild
+type RelatedOptions struct {
+	MinClients int64         // pairs read by fewer distinct clients are dropped, defaults to 5
+	Window     time.Duration // views older than this are ignored, at most and by default 90 days
+}
+
+// RebuildRelated recomputes the URLs most often read by the same clients in the last
+// 90 days, or opts.Window if it is shorter. Clients are read in batches of
+// _RELATED_CLIENT_BATCH and pairs are counted over the _RELATED_MAX_URLS_PER_CLIENT URLs
+// each client read most recently. Pairs are scored by the cosine similarity of their sets
+// of readers, clients(a and b) / sqrt(clients(a) * clients(b)), so popular URLs do not
+// appear related to everything, and only the best _RELATED_MAX_PER_URL are kept per URL.
+// Pairs read by fewer than opts.MinClients clients are never stored so that a
+// recommendation cannot reveal what an individual reader has read. It returns the
+// number of pairs stored.
+func RebuildRelated(ctx context.Context, ps types.PersistenceService, now time.Time, opts RelatedOptions) (int, error) {
+	minClients := opts.MinClients
+	if minClients <= 0 {
+		minClients = 5
+	}
+	window := _RELATED_WINDOW
+	if opts.Window > 0 && opts.Window < window {
+		window = opts.Window
+	}
+	from := now.Add(-window).UnixNano()
+
+	type pairKey struct{ a, b int64 } // a < b
+	both := make(map[pairKey]int64)
+	clients := make(map[int64]int64)
+	var after int64
+	for {
+		visits, last, err := ps.RelatedVisits(ctx, from, after, _RELATED_CLIENT_BATCH)
+		if err != nil {
+			return 0, err
+		}
+		if last == 0 {
+			break
+		}
+		after = last
+
+		// visits are grouped by client, most recent first
+		for start := 0; start < len(visits); {
+			end := start
+			for end < len(visits) && visits[end].ClientID == visits[start].ClientID {
+				end++
+			}
+			read := visits[start:min(end, start+_RELATED_MAX_URLS_PER_CLIENT)]
+			for i, v := range read {
+				clients[v.UrlID]++
+				for _, w := range read[i+1:] {
+					k := pairKey{min(v.UrlID, w.UrlID), max(v.UrlID, w.UrlID)}
+					both[k]++
+				}
+			}
+			start = end
+		}
+	}
+
+	byUrl := make(map[int64][]types.RelatedPair)
+	for k, n := range both {
+		if n < minClients {
+			continue
+		}
+		score := float64(n) / math.Sqrt(float64(clients[k.a])*float64(clients[k.b]))
+		byUrl[k.a] = append(byUrl[k.a], types.RelatedPair{UrlID: k.a, RelatedUrlID: k.b, Clients: n, Score: score})
+		byUrl[k.b] = append(byUrl[k.b], types.RelatedPair{UrlID: k.b, RelatedUrlID: k.a, Clients: n, Score: score})
+	}
+
+	kept := make([]types.RelatedPair, 0, len(byUrl))
+	for _, related := range byUrl {
+		sort.Slice(related, func(i, j int) bool {
+			if related[i].Score != related[j].Score {
+				return related[i].Score > related[j].Score
+			}
+			return related[i].RelatedUrlID < related[j].RelatedUrlID
+		})
+		kept = append(kept, related[:min(len(related), _RELATED_MAX_PER_URL)]...)
+	}
+
+	if err := ps.RelatedReplace(ctx, kept, now.UnixNano()); err != nil {
+		return 0, err
+	}
+	return len(kept), nil
+}
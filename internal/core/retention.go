@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+const (
+	_RETENTION_DEFAULT_BATCH_SIZE  = 1000
+	_RETENTION_DEFAULT_MAX_BATCHES = 100
+	_DAY                           = int64(24 * time.Hour)
+)
+
+// RetentionOptions controls a retention pass
+type RetentionOptions struct {
+	Retention  time.Duration // raw views older than this are rolled up and deleted
+	BatchSize  int32         // views deleted per statement, defaults to 1000
+	MaxBatches int           // delete statements per pass, defaults to 100
+	BatchDelay time.Duration // pause between delete statements to limit database load
+}
+
+// UTCDay returns the start of the UTC day containing ts, both in unix nanoseconds
+func UTCDay(ts int64) int64 {
+	return ts - ((ts%_DAY)+_DAY)%_DAY
+}
+
+// PruneViews rolls up raw views of whole UTC days older than opts.Retention into
+// daily per-URL aggregates and deletes them in bounded batches, oldest day first.
+// A day is only deleted after its rollup has been committed, so an interrupted pass
+// is resumed by the next one. Readers that combine raw views with rollups must skip raw
+// views of days listed in view_rollup_days. generateID supplies rollup IDs.
+func PruneViews(
+	ctx context.Context,
+	ps types.PersistenceService,
+	generateID func() (int64, error),
+	opts RetentionOptions,
+) (types.RetentionResult, error) {
+	var result types.RetentionResult
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = _RETENTION_DEFAULT_BATCH_SIZE
+	}
+	maxBatches := opts.MaxBatches
+	if maxBatches <= 0 {
+		maxBatches = _RETENTION_DEFAULT_MAX_BATCHES
+	}
+
+	cutoff := UTCDay(time.Now().Add(-opts.Retention).UnixNano())
+	batches := 0
+	for batches < maxBatches {
+		oldest, err := ps.ViewOldestBefore(ctx, cutoff)
+		if err != nil {
+			return result, err
+		}
+		if oldest == 0 {
+			return result, nil
+		}
+		day := UTCDay(oldest)
+
+		done, err := ps.ViewRollupDone(ctx, day)
+		if err != nil {
+			return result, err
+		}
+		if !done {
+			rollups, err := ps.ViewDailyAggregate(ctx, day)
+			if err != nil {
+				return result, err
+			}
+			for i := range rollups {
+				rollups[i].ID, err = generateID()
+				if err != nil {
+					return result, err
+				}
+			}
+			inserted, err := ps.ViewRollupInsert(ctx, day, rollups)
+			if err != nil {
+				return result, err
+			}
+			if inserted {
+				result.DaysRolledUp++
+			}
+		}
+
+		for batches < maxBatches {
+			deleted, err := ps.ViewDeleteBefore(ctx, day+_DAY, batchSize)
+			if err != nil {
+				return result, err
+			}
+			batches++
+			result.ViewsDeleted += deleted
+			if deleted < int64(batchSize) {
+				break
+			}
+
+			if opts.BatchDelay > 0 {
+				select {
+				case <-time.After(opts.BatchDelay):
+				case <-ctx.Done():
+					return result, ctx.Err()
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
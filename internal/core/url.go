@@ -1,40 +1,235 @@
 package core
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"os"
+	"sort"
 	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// TrailingSlashPolicy controls how a trailing slash of a path is handled
+type TrailingSlashPolicy string
+
+const (
+	TrailingSlashStrip TrailingSlashPolicy = "strip" // remove trailing slashes except for root "/"
+	TrailingSlashKeep  TrailingSlashPolicy = "keep"  // keep the path as is
+	TrailingSlashAdd   TrailingSlashPolicy = "add"   // add a trailing slash unless the last segment has an extension
 )
 
+// URLRules controls how URLs are canonicalized. The zero value reproduces the
+// original "host + pathname" behavior so that existing counts are not split.
+type URLRules struct {
+	LowercaseHost       bool                `json:"lowercase_host"`        // lowercase the host name
+	IDNA                bool                `json:"idna"`                  // convert internationalized host names to punycode (UTS #46)
+	FoldWWW             bool                `json:"fold_www"`              // treat www.example.com as example.com
+	StripIndex          bool                `json:"strip_index"`           // strip index.html and index.htm from paths
+	TrailingSlash       TrailingSlashPolicy `json:"trailing_slash"`        // defaults to TrailingSlashStrip
+	QueryAllowlist      []string            `json:"query_allowlist"`       // query parameters kept in the canonical URL
+	CaseInsensitivePath bool                `json:"case_insensitive_path"` // lowercase the path
+}
+
+// URLCanonicalizer applies per-site URLRules, falling back to default rules
+// for sites without an entry. Sites are keyed by lowercased host without "www.".
+type URLCanonicalizer struct {
+	Default URLRules            `json:"default"`
+	Sites   map[string]URLRules `json:"sites"`
+}
+
+// DefaultURLCanonicalizer applies the zero URLRules to every site
+var DefaultURLCanonicalizer = &URLCanonicalizer{}
+
+// LoadURLCanonicalizer reads canonicalization rules from a JSON file of the form
+// {"default": {...}, "sites": {"example.com": {...}}}. An empty path yields the default rules.
+func LoadURLCanonicalizer(path string) (*URLCanonicalizer, error) {
+	if path == "" {
+		return DefaultURLCanonicalizer, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &URLCanonicalizer{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("invalid url rules: %w", err)
+	}
+
+	rules := []URLRules{c.Default}
+	sites := make(map[string]URLRules, len(c.Sites))
+	for site, r := range c.Sites {
+		if _, err := idna.Lookup.ToASCII(site); err != nil {
+			return nil, fmt.Errorf("invalid url rules: invalid site %q: %w", site, err)
+		}
+		sites[siteKey(site)] = r
+		rules = append(rules, r)
+	}
+	c.Sites = sites
+
+	for _, r := range rules {
+		switch r.TrailingSlash {
+		case "", TrailingSlashStrip, TrailingSlashKeep, TrailingSlashAdd:
+		default:
+			return nil, fmt.Errorf("invalid url rules: unknown trailing_slash policy %q", r.TrailingSlash)
+		}
+	}
+
+	return c, nil
+}
+
+// Rules returns the rules that apply to the given host
+func (c *URLCanonicalizer) Rules(host string) URLRules {
+	if r, ok := c.Sites[siteKey(host)]; ok {
+		return r
+	}
+	return c.Default
+}
+
+// NormalizeURL canonicalizes a URL using the rules of its site.
+func (c *URLCanonicalizer) NormalizeURL(raw string) (string, error) {
+	u, err := parseRawURL(raw)
+	if err != nil {
+		return "", err
+	}
+	return c.Rules(u.Hostname()).normalize(u)
+}
+
 // NormalizeURL normalizes a URL to "host + pathname".
 // If parsing fails, it attempts to add "http://" and parse again.
 func NormalizeURL(raw string) (string, error) {
+	return DefaultURLCanonicalizer.NormalizeURL(raw)
+}
+
+// NormalizeURL canonicalizes a URL using these rules.
+func (r URLRules) NormalizeURL(raw string) (string, error) {
+	u, err := parseRawURL(raw)
+	if err != nil {
+		return "", err
+	}
+	return r.normalize(u)
+}
+
+func parseRawURL(raw string) (*url.URL, error) {
 	if raw == "" {
-		return "", fmt.Errorf("empty url")
+		return nil, fmt.Errorf("empty url")
 	}
 
 	u, err := url.Parse(raw)
 	if err != nil || u.Host == "" {
 		u, err = url.Parse("http://" + raw)
 		if err != nil || u.Host == "" {
-			return "", fmt.Errorf("invalid url: %w", err)
+			return nil, fmt.Errorf("invalid url: %w", err)
 		}
 	}
+	return u, nil
+}
 
-	host := u.Host
+func (r URLRules) normalize(u *url.URL) (string, error) {
 	// strip port if present
-	if i := strings.Index(host, ":"); i != -1 {
-		host = host[:i]
+	host := u.Hostname()
+	if r.LowercaseHost {
+		host = strings.ToLower(host)
+	}
+	if r.IDNA {
+		ascii, err := idna.Lookup.ToASCII(host)
+		if err != nil {
+			return "", fmt.Errorf("invalid host: %w", err)
+		}
+		host = ascii
+	}
+	if r.FoldWWW && len(host) > 4 && strings.EqualFold(host[:4], "www.") {
+		host = host[4:]
 	}
 
 	path := u.Path
 	if path == "" {
 		path = "/"
 	}
-	// remove trailing slash except for root "/"
-	if len(path) > 1 && strings.HasSuffix(path, "/") {
-		path = strings.TrimRight(path, "/")
+	if r.CaseInsensitivePath {
+		path = strings.ToLower(path)
+	}
+	if r.StripIndex {
+		for _, index := range []string{"/index.html", "/index.htm"} {
+			if len(path) >= len(index) && strings.EqualFold(path[len(path)-len(index):], index) {
+				path = path[:len(path)-len(index)+1]
+				break
+			}
+		}
+	}
+
+	switch r.TrailingSlash {
+	case TrailingSlashKeep:
+	case TrailingSlashAdd:
+		last := path[strings.LastIndex(path, "/")+1:]
+		if !strings.HasSuffix(path, "/") && !strings.Contains(last, ".") {
+			path += "/"
+		}
+	default:
+		// remove trailing slash except for root "/"
+		if len(path) > 1 && strings.HasSuffix(path, "/") {
+			path = strings.TrimRight(path, "/")
+			if path == "" {
+				path = "/"
+			}
+		}
+	}
+
+	return host + path + r.canonicalQuery(u.Query()), nil
+}
+
+// canonicalQuery returns the allowlisted query parameters sorted by name,
+// or an empty string if none are present.
+func (r URLRules) canonicalQuery(q url.Values) string {
+	if len(r.QueryAllowlist) == 0 || len(q) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(r.QueryAllowlist))
+	for _, k := range r.QueryAllowlist {
+		if _, ok := q[k]; ok {
+			keys = append(keys, k)
+		}
 	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
 
-	return host + path, nil
+	var sb strings.Builder
+	for i, k := range keys {
+		values := append([]string(nil), q[k]...)
+		sort.Strings(values)
+		for j, v := range values {
+			if i > 0 || j > 0 {
+				sb.WriteByte('&')
+			} else {
+				sb.WriteByte('?')
+			}
+			sb.WriteString(url.QueryEscape(k))
+			sb.WriteByte('=')
+			sb.WriteString(url.QueryEscape(v))
+		}
+	}
+	return sb.String()
+}
+
+// siteKey returns the lookup key of a host in URLCanonicalizer.Sites: the ASCII form
+// of the host without "www.", so Unicode and punycode spellings of a site match
+func siteKey(host string) string {
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		host = ascii
+	}
+	return strings.TrimPrefix(strings.ToLower(host), "www.")
+}
+
+// URLSite returns the host part of a normalized URL
+func URLSite(normalized string) string {
+	if i := strings.Index(normalized, "/"); i != -1 {
+		return normalized[:i]
+	}
+	return normalized
 }
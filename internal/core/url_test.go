@@ -0,0 +1,101 @@
+package core
+
+import "testing"
+
+func TestURLCanonicalizerNormalizeURL(t *testing.T) {
+	c := &URLCanonicalizer{
+		Sites: map[string]URLRules{
+			"example.com": {
+				LowercaseHost:  true,
+				IDNA:           true,
+				FoldWWW:        true,
+				StripIndex:     true,
+				QueryAllowlist: []string{"page", "id"},
+			},
+			"keep.example": {TrailingSlash: TrailingSlashKeep},
+			"add.example":  {TrailingSlash: TrailingSlashAdd},
+			"xn--bcher-kva.example": {
+				LowercaseHost: true,
+				IDNA:          true,
+			},
+			"case.example": {CaseInsensitivePath: true},
+		},
+	}
+
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"default strips scheme, port, query and fragment", "https://other.example:8080/a/b?x=1#top", "other.example/a/b"},
+		{"default without scheme", "other.example/a", "other.example/a"},
+		{"default keeps host case", "https://Other.Example/", "Other.Example/"},
+		{"default empty path is root", "https://other.example", "other.example/"},
+		{"default strips trailing slashes", "https://other.example/a//", "other.example/a"},
+		{"default keeps root", "https://other.example/", "other.example/"},
+		{"default keeps www", "https://www.other.example/a", "www.other.example/a"},
+
+		{"lowercases host", "https://EXAMPLE.com/a", "example.com/a"},
+		{"folds www", "https://www.example.com/a", "example.com/a"},
+		{"folds uppercase www", "https://WWW.Example.com/a", "example.com/a"},
+		{"strips index.html", "https://example.com/docs/index.html", "example.com/docs"},
+		{"strips index.htm at root", "https://example.com/INDEX.HTM", "example.com/"},
+		{"keeps other html", "https://example.com/docs/about.html", "example.com/docs/about.html"},
+		{"drops unlisted query", "https://example.com/a?utm_source=x", "example.com/a"},
+		{"sorts allowlisted query", "https://example.com/a?page=2&utm_source=x&id=7", "example.com/a?id=7&page=2"},
+		{"sorts repeated values", "https://example.com/a?page=2&page=1", "example.com/a?page=1&page=2"},
+		{"escapes query values", "https://example.com/a?id=a%20b%26c", "example.com/a?id=a+b%26c"},
+
+		{"converts unicode host to punycode", "https://BÜCHER.example/a", "xn--bcher-kva.example/a"},
+		{"punycode host uses the same rules", "https://xn--bcher-kva.example/a/", "xn--bcher-kva.example/a"},
+
+		{"keep policy keeps trailing slash", "https://keep.example/a/", "keep.example/a/"},
+		{"keep policy keeps missing slash", "https://keep.example/a", "keep.example/a"},
+		{"add policy adds slash", "https://add.example/a", "add.example/a/"},
+		{"add policy keeps existing slash", "https://add.example/a/", "add.example/a/"},
+		{"add policy skips files", "https://add.example/a/style.css", "add.example/a/style.css"},
+		{"add policy keeps root", "https://add.example", "add.example/"},
+
+		{"lowercases path", "https://case.example/About/Team", "case.example/about/team"},
+		{"site rules match www host", "https://www.keep.example/a/", "www.keep.example/a/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.NormalizeURL(tt.raw)
+			if err != nil {
+				t.Fatalf("NormalizeURL(%q) error: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeURL(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLCanonicalizerNormalizeURLInvalid(t *testing.T) {
+	c := &URLCanonicalizer{Default: URLRules{IDNA: true}}
+
+	for _, raw := range []string{"", "https://xn--a.example/", "https://exa_mple.com/"} {
+		if got, err := c.NormalizeURL(raw); err == nil {
+			t.Errorf("NormalizeURL(%q) = %q, want error", raw, got)
+		}
+	}
+}
+
+func TestURLCanonicalizerRules(t *testing.T) {
+	site := URLRules{FoldWWW: true}
+	c := &URLCanonicalizer{
+		Default: URLRules{LowercaseHost: true},
+		Sites:   map[string]URLRules{"example.com": site, "xn--bcher-kva.example": site},
+	}
+
+	for _, host := range []string{"example.com", "EXAMPLE.COM", "www.example.com", "bücher.example", "www.xn--bcher-kva.example"} {
+		if got := c.Rules(host); !got.FoldWWW {
+			t.Errorf("Rules(%q) = %+v, want site rules", host, got)
+		}
+	}
+	if got := c.Rules("other.example"); !got.LowercaseHost || got.FoldWWW {
+		t.Errorf("Rules(other.example) = %+v, want default rules", got)
+	}
+}
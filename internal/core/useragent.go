@@ -0,0 +1,196 @@
+package core
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// _UA_MAX_VERSION_LEN bounds browser versions, which are stored in a VARCHAR(16) column
+const _UA_MAX_VERSION_LEN = 8
+
+var _crawler_pattern = regexp.MustCompile(`(?i)(bot|crawler|spider|crawl|fetcher|facebookexternalhit|facebookcatalog|ia_archiver|slurp|mediapartners|adsbot|headlesschrome|lighthouse)`)
+
+// browserRule maps a User-Agent token to a browser family. Rules are checked in
+// order, so more specific tokens (Edge, Opera, ...) must come before Chrome and Safari.
+type browserRule struct {
+	token  string
+	family string
+}
+
+var _browser_rules = []browserRule{
+	{"Edg/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"EdgiOS/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Opera/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"YaBrowser/", "Yandex"},
+	{"Whale/", "Whale"},
+	{"Vivaldi/", "Vivaldi"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Version/", "Safari"},
+}
+
+// brands reported by UA-CH that map to a well known browser family
+var _brand_families = map[string]string{
+	"Google Chrome":    "Chrome",
+	"Microsoft Edge":   "Edge",
+	"Opera":            "Opera",
+	"Samsung Internet": "Samsung Internet",
+	"YaBrowser":        "Yandex",
+	"Whale":            "Whale",
+	"Brave":            "Brave",
+	"Vivaldi":          "Vivaldi",
+	"Chromium":         "Chromium",
+}
+
+// clientHints is the subset of NavigatorUAData serialized by client.js
+type clientHints struct {
+	Brands []struct {
+		Brand   string `json:"brand"`
+		Version string `json:"version"`
+	} `json:"brands"`
+	Mobile   *bool  `json:"mobile"`
+	Platform string `json:"platform"`
+}
+
+// ParseUserAgent parses a raw User-Agent string and the JSON encoded
+// User-Agent Client Hints into normalized device dimensions.
+// Client Hints take precedence when present since they are not frozen.
+func ParseUserAgent(ua string, uad string) types.DeviceInfo {
+	info := types.DeviceInfo{
+		Browser:     "Unknown",
+		OS:          "Unknown",
+		DeviceClass: types.DeviceClassUnknown,
+	}
+
+	if ua != "" {
+		info.Browser, info.BrowserVersion = parseBrowser(ua)
+		info.OS = parseOS(ua)
+		info.DeviceClass = parseDeviceClass(ua)
+	}
+
+	var hints clientHints
+	if uad != "" && uad != "null" && uad != "undefined" && json.Unmarshal([]byte(uad), &hints) == nil {
+		for _, b := range hints.Brands {
+			// prefer the most specific brand; Chromium is only a fallback
+			family, ok := _brand_families[b.Brand]
+			if !ok {
+				continue
+			}
+			if family == "Chromium" && info.Browser != "Unknown" {
+				continue
+			}
+			info.Browser = family
+			info.BrowserVersion = majorVersion(b.Version)
+			if family != "Chromium" {
+				break
+			}
+		}
+		if hints.Platform != "" {
+			info.OS = normalizePlatform(hints.Platform)
+		}
+		if hints.Mobile != nil && info.DeviceClass != types.DeviceClassBot {
+			// the hint decides between mobile and not; tablets report false and keep
+			// their class, while a mobile class from a desktop-mode UA becomes desktop
+			switch {
+			case *hints.Mobile:
+				info.DeviceClass = types.DeviceClassMobile
+			case info.DeviceClass == types.DeviceClassMobile, info.DeviceClass == types.DeviceClassUnknown:
+				info.DeviceClass = types.DeviceClassDesktop
+			}
+		}
+	}
+
+	// Mobile is derived from the final class so the two never disagree
+	info.Mobile = info.DeviceClass == types.DeviceClassMobile
+	return info
+}
+
+// IsCrawler reports whether the User-Agent belongs to a known crawler.
+func IsCrawler(ua string) bool {
+	return _crawler_pattern.MatchString(ua)
+}
+
+func parseBrowser(ua string) (string, string) {
+	if IsCrawler(ua) {
+		return "Bot", ""
+	}
+	for _, rule := range _browser_rules {
+		i := strings.Index(ua, rule.token)
+		if i == -1 {
+			continue
+		}
+		if rule.family == "Safari" && !strings.Contains(ua, "Safari/") {
+			continue
+		}
+		return rule.family, majorVersion(ua[i+len(rule.token):])
+	}
+	if strings.Contains(ua, "Trident/") || strings.Contains(ua, "MSIE ") {
+		return "Internet Explorer", ""
+	}
+	return "Other", ""
+}
+
+func parseOS(ua string) string {
+	switch {
+	case strings.Contains(ua, "Windows"):
+		return "Windows"
+	case strings.Contains(ua, "Android"):
+		return "Android"
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPod"):
+		return "iOS"
+	case strings.Contains(ua, "iPad"):
+		return "iPadOS"
+	case strings.Contains(ua, "CrOS"):
+		return "Chrome OS"
+	case strings.Contains(ua, "Mac OS X"), strings.Contains(ua, "Macintosh"):
+		return "macOS"
+	case strings.Contains(ua, "Linux"):
+		return "Linux"
+	}
+	return "Other"
+}
+
+func parseDeviceClass(ua string) string {
+	switch {
+	case IsCrawler(ua):
+		return types.DeviceClassBot
+	case strings.Contains(ua, "iPad"), strings.Contains(ua, "Tablet"),
+		strings.Contains(ua, "Android") && !strings.Contains(ua, "Mobile"):
+		return types.DeviceClassTablet
+	case strings.Contains(ua, "Mobi"), strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPod"):
+		return types.DeviceClassMobile
+	}
+	return types.DeviceClassDesktop
+}
+
+// normalizePlatform maps UA-CH platform names to the names used by parseOS. The
+// platform is supplied by the client, so unknown names map to "Other".
+func normalizePlatform(platform string) string {
+	switch platform {
+	case "Chrome OS", "Chromium OS":
+		return "Chrome OS"
+	case "Android", "iOS", "iPadOS", "Linux", "macOS", "Windows":
+		return platform
+	case "", "Unknown":
+		return "Unknown"
+	}
+	return "Other"
+}
+
+// majorVersion returns the leading run of digits of a version string, at most
+// _UA_MAX_VERSION_LEN of them
+func majorVersion(v string) string {
+	end := 0
+	for end < len(v) && end < _UA_MAX_VERSION_LEN && v[end] >= '0' && v[end] <= '9' {
+		end++
+	}
+	return v[:end]
+}
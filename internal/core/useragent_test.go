@@ -0,0 +1,70 @@
+package core
+
+import (
+	"testing"
+
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	const (
+		chromeWindows = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+		chromeAndroid = "Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36"
+		safariIPhone  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+		safariIPad    = "Mozilla/5.0 (iPad; CPU OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+		firefoxLinux  = "Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0"
+		edgeWindows   = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.51"
+		googlebot     = "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"
+	)
+
+	tests := []struct {
+		name string
+		ua   string
+		uad  string
+		want types.DeviceInfo
+	}{
+		{"chrome desktop", chromeWindows, "", types.DeviceInfo{Browser: "Chrome", BrowserVersion: "124", OS: "Windows", DeviceClass: types.DeviceClassDesktop}},
+		{"chrome mobile", chromeAndroid, "", types.DeviceInfo{Browser: "Chrome", BrowserVersion: "124", OS: "Android", DeviceClass: types.DeviceClassMobile, Mobile: true}},
+		{"safari iphone", safariIPhone, "", types.DeviceInfo{Browser: "Safari", BrowserVersion: "17", OS: "iOS", DeviceClass: types.DeviceClassMobile, Mobile: true}},
+		{"safari ipad", safariIPad, "", types.DeviceInfo{Browser: "Safari", BrowserVersion: "17", OS: "iPadOS", DeviceClass: types.DeviceClassTablet}},
+		{"firefox linux", firefoxLinux, "", types.DeviceInfo{Browser: "Firefox", BrowserVersion: "125", OS: "Linux", DeviceClass: types.DeviceClassDesktop}},
+		{"edge before chrome", edgeWindows, "", types.DeviceInfo{Browser: "Edge", BrowserVersion: "124", OS: "Windows", DeviceClass: types.DeviceClassDesktop}},
+		{"crawler", googlebot, "", types.DeviceInfo{Browser: "Bot", OS: "Other", DeviceClass: types.DeviceClassBot}},
+		{"empty", "", "", types.DeviceInfo{Browser: "Unknown", OS: "Unknown", DeviceClass: types.DeviceClassUnknown}},
+
+		{
+			"hints override brand and platform", chromeWindows,
+			`{"brands":[{"brand":"Chromium","version":"124"},{"brand":"Microsoft Edge","version":"124"}],"mobile":false,"platform":"Windows"}`,
+			types.DeviceInfo{Browser: "Edge", BrowserVersion: "124", OS: "Windows", DeviceClass: types.DeviceClassDesktop},
+		},
+		{
+			"mobile hint makes desktop ua mobile", chromeWindows, `{"mobile":true,"platform":"Android"}`,
+			types.DeviceInfo{Browser: "Chrome", BrowserVersion: "124", OS: "Android", DeviceClass: types.DeviceClassMobile, Mobile: true},
+		},
+		{
+			"desktop hint makes mobile ua desktop", chromeAndroid, `{"mobile":false}`,
+			types.DeviceInfo{Browser: "Chrome", BrowserVersion: "124", OS: "Android", DeviceClass: types.DeviceClassDesktop},
+		},
+		{
+			"desktop hint keeps tablet", safariIPad, `{"mobile":false}`,
+			types.DeviceInfo{Browser: "Safari", BrowserVersion: "17", OS: "iPadOS", DeviceClass: types.DeviceClassTablet},
+		},
+		{
+			"mobile hint keeps bot", googlebot, `{"mobile":true}`,
+			types.DeviceInfo{Browser: "Bot", OS: "Other", DeviceClass: types.DeviceClassBot},
+		},
+		{
+			"unknown platform hint", chromeWindows, `{"platform":"TempleOS"}`,
+			types.DeviceInfo{Browser: "Chrome", BrowserVersion: "124", OS: "Other", DeviceClass: types.DeviceClassDesktop},
+		},
+		{"invalid hints are ignored", chromeAndroid, "{", types.DeviceInfo{Browser: "Chrome", BrowserVersion: "124", OS: "Android", DeviceClass: types.DeviceClassMobile, Mobile: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseUserAgent(tt.ua, tt.uad); got != tt.want {
+				t.Errorf("ParseUserAgent() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
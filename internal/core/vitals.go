@@ -0,0 +1,151 @@
+package core
+
+import (
+	"errors"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+var (
+	ErrUnknownVitalMetric = errors.New("core: unknown web vital metric")
+	ErrInvalidVitalValue  = errors.New("core: web vital value out of range")
+)
+
+// vitalMetric describes how a metric is validated and stored
+type vitalMetric struct {
+	code  int32
+	name  string
+	unit  string
+	scale float64 // stored value = reported value * scale
+	max   float64 // largest accepted reported value
+}
+
+var _vital_metrics = []vitalMetric{
+	{code: types.VitalLCP, name: "LCP", unit: "ms", scale: 1, max: 120_000},
+	{code: types.VitalINP, name: "INP", unit: "ms", scale: 1, max: 60_000},
+	{code: types.VitalCLS, name: "CLS", unit: "", scale: 1000, max: 100},
+	{code: types.VitalFCP, name: "FCP", unit: "ms", scale: 1, max: 120_000},
+	{code: types.VitalTTFB, name: "TTFB", unit: "ms", scale: 1, max: 120_000},
+}
+
+// Stored values are counted in buckets whose bounds grow by _VITAL_BUCKET_RATIO, so
+// percentiles read from the histogram are within 2.5% of the exact value.
+const _VITAL_BUCKET_RATIO = 1.05
+
+// VitalMeasurement validates a metric reported by a client, e.g. "LCP" in milliseconds
+// or "CLS" as a unitless score, and converts it into its histogram bucket.
+func VitalMeasurement(name string, value float64) (types.VitalMeasurement, error) {
+	for _, m := range _vital_metrics {
+		if m.name != name {
+			continue
+		}
+		if math.IsNaN(value) || value < 0 || value > m.max {
+			return types.VitalMeasurement{}, ErrInvalidVitalValue
+		}
+		return types.VitalMeasurement{Metric: m.code, Bucket: VitalBucket(int32(math.Round(value * m.scale)))}, nil
+	}
+	return types.VitalMeasurement{}, ErrUnknownVitalMetric
+}
+
+// VitalBucket returns the histogram bucket of a stored value. Bucket 0 holds zero,
+// bucket b > 0 holds values in [ratio^(b-1), ratio^b).
+func VitalBucket(value int32) int16 {
+	if value <= 0 {
+		return 0
+	}
+	return int16(1 + math.Floor(math.Log(float64(value))/math.Log(_VITAL_BUCKET_RATIO)))
+}
+
+// vitalBucketValue returns the stored value a bucket stands for, the geometric middle of its bounds
+func vitalBucketValue(bucket int16) float64 {
+	if bucket <= 0 {
+		return 0
+	}
+	return math.Round(math.Pow(_VITAL_BUCKET_RATIO, float64(bucket)-0.5))
+}
+
+// VitalStats computes the p50, p75 and p95 of every metric over all buckets and
+// per device class. Metrics without samples are omitted.
+func VitalStats(buckets []types.VitalBucketCount) []types.VitalMetricStats {
+	type key struct {
+		metric      int32
+		deviceClass string
+	}
+	all := make(map[int32]map[int16]int64)
+	byDevice := make(map[key]map[int16]int64)
+	for _, b := range buckets {
+		if all[b.Metric] == nil {
+			all[b.Metric] = make(map[int16]int64)
+		}
+		all[b.Metric][b.Bucket] += b.Samples
+		k := key{b.Metric, b.DeviceClass}
+		if byDevice[k] == nil {
+			byDevice[k] = make(map[int16]int64)
+		}
+		byDevice[k][b.Bucket] += b.Samples
+	}
+
+	out := make([]types.VitalMetricStats, 0, len(all))
+	for _, m := range _vital_metrics {
+		histogram, ok := all[m.code]
+		if !ok {
+			continue
+		}
+		stats := types.VitalMetricStats{
+			Metric:           m.name,
+			Unit:             m.unit,
+			VitalPercentiles: vitalPercentiles(histogram, m.scale),
+			Devices:          make([]types.VitalDeviceStats, 0),
+		}
+		for k, histogram := range byDevice {
+			if k.metric != m.code {
+				continue
+			}
+			stats.Devices = append(stats.Devices, types.VitalDeviceStats{
+				DeviceClass:      k.deviceClass,
+				VitalPercentiles: vitalPercentiles(histogram, m.scale),
+			})
+		}
+		sort.Slice(stats.Devices, func(i, j int) bool {
+			if stats.Devices[i].Samples != stats.Devices[j].Samples {
+				return stats.Devices[i].Samples > stats.Devices[j].Samples
+			}
+			return stats.Devices[i].DeviceClass < stats.Devices[j].DeviceClass
+		})
+		out = append(out, stats)
+	}
+	return out
+}
+
+// vitalPercentiles returns the nearest-rank percentiles of a histogram in reported units
+func vitalPercentiles(histogram map[int16]int64, scale float64) types.VitalPercentiles {
+	buckets := slices.Sorted(maps.Keys(histogram))
+	var total int64
+	for _, b := range buckets {
+		total += histogram[b]
+	}
+	return types.VitalPercentiles{
+		Samples: total,
+		P50:     vitalBucketValue(percentile(histogram, buckets, total, 50)) / scale,
+		P75:     vitalBucketValue(percentile(histogram, buckets, total, 75)) / scale,
+		P95:     vitalBucketValue(percentile(histogram, buckets, total, 95)) / scale,
+	}
+}
+
+// percentile returns the bucket holding the nearest-rank p-th percentile of a non-empty
+// histogram with the given sorted buckets and total number of samples
+func percentile(histogram map[int16]int64, buckets []int16, total int64, p int64) int16 {
+	rank := max((p*total+99)/100, 1)
+	var seen int64
+	for _, b := range buckets {
+		seen += histogram[b]
+		if seen >= rank {
+			return b
+		}
+	}
+	return buckets[len(buckets)-1]
+}
@@ -0,0 +1,98 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// _API_KEY_TOUCH_INTERVAL limits how often last_used_at is written for a busy key
+const _API_KEY_TOUCH_INTERVAL = int64(time.Minute)
+
+func apiKeyFromRow(r database.ApiKey) types.ApiKey {
+	return types.ApiKey{
+		ID:         r.ID,
+		Name:       r.Name,
+		Scopes:     strings.Fields(r.Scopes),
+		CreatedAt:  r.CreatedAt,
+		LastUsedAt: r.LastUsedAt,
+		RevokedAt:  r.RevokedAt,
+	}
+}
+
+// ApiKeyCreate stores a new API key by the hash of its secret
+func (g *PersistenceClient) ApiKeyCreate(ctx context.Context, id int64, name string, keyHash []byte, scopes []string) (types.ApiKey, error) {
+	now := time.Now().UnixNano()
+	err := g.db.ApiKeyInsert(ctx, database.ApiKeyInsertParams{
+		ID:        id,
+		Name:      name,
+		KeyHash:   keyHash,
+		Scopes:    strings.Join(scopes, " "),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return types.ApiKey{}, err
+	}
+	return types.ApiKey{ID: id, Name: name, Scopes: scopes, CreatedAt: now}, nil
+}
+
+// ApiKeyLookupByHash returns the API key with the given hash, revoked or not.
+// It returns false if there is none.
+func (g *PersistenceClient) ApiKeyLookupByHash(ctx context.Context, keyHash []byte) (types.ApiKey, bool, error) {
+	row, err := g.db.ApiKeyLookupByHash(ctx, keyHash)
+	if err == sql.ErrNoRows {
+		return types.ApiKey{}, false, nil
+	}
+	if err != nil {
+		return types.ApiKey{}, false, err
+	}
+	return apiKeyFromRow(row), true, nil
+}
+
+// ApiKeyLookupByID returns the API key with the given ID. It returns false if there is none.
+func (g *PersistenceClient) ApiKeyLookupByID(ctx context.Context, id int64) (types.ApiKey, bool, error) {
+	row, err := g.db.ApiKeyLookupByID(ctx, id)
+	if err == sql.ErrNoRows {
+		return types.ApiKey{}, false, nil
+	}
+	if err != nil {
+		return types.ApiKey{}, false, err
+	}
+	return apiKeyFromRow(row), true, nil
+}
+
+// ApiKeyList returns all API keys including revoked ones, oldest first
+func (g *PersistenceClient) ApiKeyList(ctx context.Context) ([]types.ApiKey, error) {
+	rows, err := g.db.ApiKeyList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]types.ApiKey, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, apiKeyFromRow(r))
+	}
+	return out, nil
+}
+
+// ApiKeyRevoke revokes an API key. It returns false if the key does not exist or is already revoked.
+func (g *PersistenceClient) ApiKeyRevoke(ctx context.Context, id int64) (bool, error) {
+	n, err := g.db.ApiKeyRevoke(ctx, database.ApiKeyRevokeParams{
+		RevokedAt: time.Now().UnixNano(),
+		ID:        id,
+	})
+	return n > 0, err
+}
+
+// ApiKeyTouch records that an API key was used, at most once per _API_KEY_TOUCH_INTERVAL
+func (g *PersistenceClient) ApiKeyTouch(ctx context.Context, id int64) error {
+	now := time.Now().UnixNano()
+	return g.db.ApiKeyTouch(ctx, database.ApiKeyTouchParams{
+		Now:    now,
+		ID:     id,
+		Before: now - _API_KEY_TOUCH_INTERVAL,
+	})
+}
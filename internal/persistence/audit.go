@@ -0,0 +1,53 @@
+package persistence
+
+import (
+	"context"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// AuditRecord appends an event to the audit log
+func (g *PersistenceClient) AuditRecord(ctx context.Context, event types.AuditEvent) error {
+	return g.db.AuditEventInsert(ctx, database.AuditEventInsertParams{
+		ID:          event.ID,
+		ActorKeyID:  event.ActorKeyID,
+		Action:      event.Action,
+		Target:      event.Target,
+		BeforeValue: string(event.Before),
+		AfterValue:  string(event.After),
+		CreatedAt:   event.CreatedAt,
+	})
+}
+
+// AuditList returns up to limit audit events selected by filter, newest first
+func (g *PersistenceClient) AuditList(ctx context.Context, filter types.AuditFilter, limit int32) ([]types.AuditEvent, error) {
+	rows, err := g.db.AuditEventList(ctx, database.AuditEventListParams{
+		FromTs:          filter.From,
+		ToTs:            filter.To,
+		AnyActor:        filter.AnyActor,
+		ActorKeyID:      filter.ActorKeyID,
+		Action:          filter.Action,
+		Target:          filter.Target,
+		CursorCreatedAt: filter.CursorCreatedAt,
+		CursorID:        filter.CursorID,
+		Limit:           limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]types.AuditEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, types.AuditEvent{
+			ID:         row.ID,
+			ActorKeyID: row.ActorKeyID,
+			Action:     row.Action,
+			Target:     row.Target,
+			Before:     []byte(row.BeforeValue),
+			After:      []byte(row.AfterValue),
+			CreatedAt:  row.CreatedAt,
+		})
+	}
+	return events, nil
+}
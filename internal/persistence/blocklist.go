@@ -0,0 +1,103 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+func blockEntryFromRow(r database.Blocklist) types.BlockEntry {
+	return types.BlockEntry{
+		ID:        r.ID,
+		Kind:      types.BlockKind(r.Kind),
+		Value:     r.Value,
+		Reason:    r.Reason,
+		CreatedBy: r.CreatedBy,
+		CreatedAt: r.CreatedAt,
+		ExpiresAt: r.ExpiresAt,
+	}
+}
+
+func blockEntriesFromRows(rows []database.Blocklist) []types.BlockEntry {
+	out := make([]types.BlockEntry, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, blockEntryFromRow(r))
+	}
+	return out
+}
+
+// BlocklistAdd stores entry, replacing the reason, creator and expiry of an existing
+// entry with the same kind and value, and returns the stored entry
+func (g *PersistenceClient) BlocklistAdd(ctx context.Context, entry types.BlockEntry) (types.BlockEntry, error) {
+	err := g.db.BlocklistUpsert(ctx, database.BlocklistUpsertParams{
+		ID:        entry.ID,
+		Kind:      int32(entry.Kind),
+		Value:     entry.Value,
+		Reason:    entry.Reason,
+		CreatedBy: entry.CreatedBy,
+		CreatedAt: entry.CreatedAt,
+		ExpiresAt: entry.ExpiresAt,
+	})
+	if err != nil {
+		return types.BlockEntry{}, err
+	}
+
+	row, err := g.db.BlocklistLookup(ctx, database.BlocklistLookupParams{
+		Kind:  int32(entry.Kind),
+		Value: entry.Value,
+	})
+	if err != nil {
+		return types.BlockEntry{}, err
+	}
+	return blockEntryFromRow(row), nil
+}
+
+// BlocklistRemove deletes a blocklist entry and returns it. It returns false if there is no such entry.
+func (g *PersistenceClient) BlocklistRemove(ctx context.Context, id int64) (types.BlockEntry, bool, error) {
+	row, err := g.db.BlocklistLookupByID(ctx, id)
+	if err == sql.ErrNoRows {
+		return types.BlockEntry{}, false, nil
+	}
+	if err != nil {
+		return types.BlockEntry{}, false, err
+	}
+
+	n, err := g.db.BlocklistDelete(ctx, id)
+	if err != nil {
+		return types.BlockEntry{}, false, err
+	}
+	return blockEntryFromRow(row), n > 0, nil
+}
+
+// BlocklistList returns all blocklist entries including expired ones, oldest first
+func (g *PersistenceClient) BlocklistList(ctx context.Context) ([]types.BlockEntry, error) {
+	rows, err := g.db.BlocklistList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return blockEntriesFromRows(rows), nil
+}
+
+// BlocklistActive returns the blocklist entries that have not expired at now
+func (g *PersistenceClient) BlocklistActive(ctx context.Context, now int64) ([]types.BlockEntry, error) {
+	rows, err := g.db.BlocklistActive(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	return blockEntriesFromRows(rows), nil
+}
+
+// BlocklistDeleteExpired deletes the blocklist entries that expired before now
+func (g *PersistenceClient) BlocklistDeleteExpired(ctx context.Context, now int64) (int64, error) {
+	return g.db.BlocklistDeleteExpired(ctx, now)
+}
+
+// BlocklistFingerprintClients returns the clients that checked in with any of the fingerprint hashes
+func (g *PersistenceClient) BlocklistFingerprintClients(ctx context.Context, fingerprints []string) ([]int64, error) {
+	if len(fingerprints) == 0 {
+		return nil, nil
+	}
+	return g.db.BlocklistFingerprintClients(ctx, fingerprints)
+}
@@ -0,0 +1,85 @@
+package persistence
+
+import (
+	"context"
+	"strings"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+var _like_escaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// siteURLPattern returns a LIKE pattern matching all normalized URLs of a site.
+// An empty site matches every URL.
+func siteURLPattern(site string) string {
+	if site == "" {
+		return "%"
+	}
+	return _like_escaper.Replace(site) + "/%"
+}
+
+// CampaignStats returns the top limit campaigns by views carrying UTM parameters in
+// [from, to), each with its top pagesPerCampaign landing pages.
+func (g *PersistenceClient) CampaignStats(ctx context.Context, site string, from int64, to int64, limit int32, pagesPerCampaign int32) ([]types.CampaignStatsEntry, error) {
+	pattern := siteURLPattern(site)
+	totals, err := g.db.CampaignStats(ctx, database.CampaignStatsParams{
+		FromTs:     from,
+		ToTs:       to,
+		UrlPattern: pattern,
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	pages, err := g.db.CampaignLandingPages(ctx, database.CampaignLandingPagesParams{
+		FromTs:           from,
+		ToTs:             to,
+		UrlPattern:       pattern,
+		PagesPerCampaign: int64(pagesPerCampaign),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type campaignKey struct{ source, medium, name string }
+	out := make([]types.CampaignStatsEntry, 0, len(totals))
+	index := make(map[campaignKey]int, len(totals))
+	for _, r := range totals {
+		index[campaignKey{r.UtmSource, r.UtmMedium, r.UtmCampaign}] = len(out)
+		out = append(out, types.CampaignStatsEntry{
+			Source:       r.UtmSource,
+			Medium:       r.UtmMedium,
+			Name:         r.UtmCampaign,
+			Count:        r.Count,
+			LandingPages: make([]types.CampaignLandingPage, 0, 1),
+		})
+	}
+	// pages are ordered by count; those of campaigns beyond the limit are dropped
+	for _, r := range pages {
+		i, ok := index[campaignKey{r.UtmSource, r.UtmMedium, r.UtmCampaign}]
+		if !ok {
+			continue
+		}
+		out[i].LandingPages = append(out[i].LandingPages, types.CampaignLandingPage{URL: r.Url, Count: r.Count})
+	}
+	return out, nil
+}
+
+// CampaignLandingPages returns the top limit landing pages by views carrying UTM parameters in [from, to)
+func (g *PersistenceClient) CampaignLandingPages(ctx context.Context, site string, from int64, to int64, limit int32) ([]types.CampaignLandingPage, error) {
+	rows, err := g.db.CampaignLandingPageTotals(ctx, database.CampaignLandingPageTotalsParams{
+		FromTs:     from,
+		ToTs:       to,
+		UrlPattern: siteURLPattern(site),
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]types.CampaignLandingPage, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, types.CampaignLandingPage{URL: r.Url, Count: r.Count})
+	}
+	return out, nil
+}
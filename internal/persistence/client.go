@@ -57,33 +57,61 @@ func (g *PersistenceClient) ClientRegisterFingerprint(
 	})
 }
 
-func (g *PersistenceClient) ViewInsertWithCount(ctx context.Context, id int64, urlID int64, clientID int64, countID int64) error {
+// ViewInsertWithCount records a view of view.URL and increments its view count in one transaction.
+// The URL record is created with urlID if it does not exist yet. It returns the canonical URL
+// the view was recorded for, which differs from view.URL if that is an alias.
+func (g *PersistenceClient) ViewInsertWithCount(ctx context.Context, view types.ViewEvent, urlID int64, countID int64) (string, error) {
 	// Start a transaction
 	tx, err := g.pool.BeginTx(ctx, &sql.TxOptions{
 		Isolation: sql.LevelSerializable,
 	})
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer tx.Rollback()
 
 	// Create a new queries instance using the transaction
 	txQueries := database.New(tx)
+
+	url, err := viewInsertWithCount(ctx, txQueries, view, urlID, countID, time.Now().UnixNano())
+	if err != nil {
+		return "", err
+	}
+
+	// Commit the transaction
+	return url, tx.Commit()
+}
+
+// viewInsertWithCount records a view created at createdAt and increments its view count using txQueries,
+// which must be bound to a transaction. It returns the canonical URL of the view.
+func viewInsertWithCount(ctx context.Context, txQueries *database.Queries, view types.ViewEvent, urlID int64, countID int64, createdAt int64) (string, error) {
 	now := time.Now().UnixNano()
 
+	urlID, url, err := urlGetOrCreate(ctx, txQueries, urlID, view.URL, now)
+	if err != nil {
+		return "", err
+	}
+
 	// Insert the view
 	err = txQueries.ViewInsert(ctx, database.ViewInsertParams{
-		ID:        id,
-		UrlID:     urlID,
-		ClientID:  clientID,
-		CreatedAt: now,
+		ID:            view.ID,
+		UrlID:         urlID,
+		ClientID:      view.ClientID,
+		Referrer:      view.Referrer.Source,
+		ReferrerClass: view.Referrer.Class,
+		UtmSource:     view.Campaign.Source,
+		UtmMedium:     view.Campaign.Medium,
+		UtmCampaign:   view.Campaign.Name,
+		UtmTerm:       view.Campaign.Term,
+		UtmContent:    view.Campaign.Content,
+		CreatedAt:     createdAt,
 	})
 	if err != nil {
 		// If insert failed with duplicate entry (unlikely since no unique constraint), treat as no-op
 		if me, ok := err.(*mysql.MySQLError); ok && me.Number == 1062 {
-			return nil
+			return url, nil
 		}
-		return err
+		return "", err
 	}
 
 	// Lookup view count row inside transaction. If none, insert; handle race by falling back to update on duplicate.
@@ -103,14 +131,14 @@ func (g *PersistenceClient) ViewInsertWithCount(ctx context.Context, id int64, u
 						UpdatedAt: now,
 						UrlID:     urlID,
 					}); err != nil {
-						return err
+						return "", err
 					}
 				} else {
-					return err
+					return "", err
 				}
 			}
 		} else {
-			return err
+			return "", err
 		}
 	} else {
 		// count row exists -> update it
@@ -118,58 +146,70 @@ func (g *PersistenceClient) ViewInsertWithCount(ctx context.Context, id int64, u
 			UpdatedAt: now,
 			UrlID:     urlID,
 		}); err != nil {
-			return err
+			return "", err
 		}
 	}
 
-	// Commit the transaction
-	return tx.Commit()
-}
-
-func (g *PersistenceClient) UrlLookupByUrl(ctx context.Context, url string) (types.Url, error) {
-	return g.db.UrlLookupByUrl(ctx, url)
-}
-
-func (g *PersistenceClient) UrlInsert(ctx context.Context, id int64, url string) error {
-	return g.db.UrlInsert(ctx, database.UrlInsertParams{
-		ID:        id,
-		Url:       url,
-		CreatedAt: time.Now().UnixNano(),
-	})
+	return url, nil
 }
 
 func (g *PersistenceClient) ViewCountLookup(ctx context.Context, urlID int64) (types.ViewCount, error) {
 	return g.db.ViewCountLookup(ctx, urlID)
 }
 
-func (g *PersistenceClient) LikeInsertWithCount(ctx context.Context, id int64, urlID int64, clientID int64, countID int64) error {
+// LikeInsertWithCount records a like of url by clientID and increments its like count in one transaction.
+// The URL record is created with urlID if it does not exist yet. It returns the canonical URL
+// the like was recorded for, which differs from url if that is an alias.
+func (g *PersistenceClient) LikeInsertWithCount(ctx context.Context, id int64, url string, clientID int64, urlID int64, countID int64) (string, error) {
 	// Start a transaction
 	tx, err := g.pool.BeginTx(ctx, &sql.TxOptions{
 		Isolation: sql.LevelSerializable,
 	})
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer tx.Rollback()
 
 	// Create a new queries instance using the transaction
 	txQueries := database.New(tx)
+
+	url, inserted, err := likeInsertWithCount(ctx, txQueries, id, url, clientID, urlID, countID, time.Now().UnixNano())
+	if err != nil {
+		return "", err
+	}
+	if !inserted {
+		return url, nil
+	}
+
+	// Commit the transaction
+	return url, tx.Commit()
+}
+
+// likeInsertWithCount records a like created at createdAt and increments its like count using txQueries,
+// which must be bound to a transaction. It returns the canonical URL of the like and false if the
+// client already liked the URL.
+func likeInsertWithCount(ctx context.Context, txQueries *database.Queries, id int64, url string, clientID int64, urlID int64, countID int64, createdAt int64) (string, bool, error) {
 	now := time.Now().UnixNano()
 
+	urlID, url, err := urlGetOrCreate(ctx, txQueries, urlID, url, now)
+	if err != nil {
+		return "", false, err
+	}
+
 	// Insert the like
 	err = txQueries.LikeInsert(ctx, database.LikeInsertParams{
 		ID:        id,
 		UrlID:     urlID,
 		ClientID:  clientID,
-		CreatedAt: now,
+		CreatedAt: createdAt,
 	})
 	if err != nil {
 		// If this is a duplicate like (client already liked this URL), treat as idempotent no-op.
 		if me, ok := err.(*mysql.MySQLError); ok && me.Number == 1062 {
 			// Do not increment count when like already exists.
-			return nil
+			return url, false, nil
 		}
-		return err
+		return "", false, err
 	}
 
 	// Lookup like count row inside transaction. If none, insert; handle race by falling back to update on duplicate.
@@ -189,14 +229,14 @@ func (g *PersistenceClient) LikeInsertWithCount(ctx context.Context, id int64, u
 						UpdatedAt: now,
 						UrlID:     urlID,
 					}); err != nil {
-						return err
+						return "", false, err
 					}
 				} else {
-					return err
+					return "", false, err
 				}
 			}
 		} else {
-			return err
+			return "", false, err
 		}
 	} else {
 		// count row exists -> update it
@@ -204,12 +244,11 @@ func (g *PersistenceClient) LikeInsertWithCount(ctx context.Context, id int64, u
 			UpdatedAt: now,
 			UrlID:     urlID,
 		}); err != nil {
-			return err
+			return "", false, err
 		}
 	}
 
-	// Commit the transaction
-	return tx.Commit()
+	return url, true, nil
 }
 
 func (g *PersistenceClient) LikeCountLookup(ctx context.Context, urlID int64) (types.LikeCount, error) {
@@ -217,9 +256,17 @@ func (g *PersistenceClient) LikeCountLookup(ctx context.Context, urlID int64) (t
 }
 
 // BulkCountsByUrls returns view and like counts for the provided normalized URLs.
+// URLs that are aliases report the counts of their canonical URL.
 // It delegates to the generated SQL helper and maps the result into types.BulkCountEntry.
 func (g *PersistenceClient) BulkCountsByUrls(ctx context.Context, urls []string) ([]types.BulkCountEntry, error) {
-	rows, err := g.db.BulkCountsByUrls(ctx, urls)
+	hashes := make([][]byte, 0, len(urls))
+	for _, url := range urls {
+		hashes = append(hashes, urlHash(url))
+	}
+	rows, err := g.db.BulkCountsByUrls(ctx, database.BulkCountsByUrlsParams{
+		UrlHashes:   hashes,
+		AliasHashes: hashes,
+	})
 	if err != nil {
 		return nil, err
 	}
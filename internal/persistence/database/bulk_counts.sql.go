@@ -12,31 +12,48 @@ import (
 
 const bulkCountsByUrls = `-- name: BulkCountsByUrls :many
 SELECT
-  u.url AS url,
-  COALESCE(vc.count, 0) AS view_count,
-  COALESCE(lc.count, 0) AS like_count
-FROM urls u
-LEFT JOIN view_counts vc ON vc.url_id = u.id
-LEFT JOIN like_counts lc ON lc.url_id = u.id
-WHERE u.url IN (/*SLICE:urls*/?)
+  r.url AS url,
+  COALESCE(vc.count, 0) + COALESCE(o.view_offset, 0) AS view_count,
+  COALESCE(lc.count, 0) + COALESCE(o.like_offset, 0) AS like_count
+FROM (
+  SELECT u.url AS url, u.id AS url_id FROM urls u WHERE u.url_hash IN (/*SLICE:url_hashes*/?)
+  UNION ALL
+  SELECT a.alias AS url, a.url_id AS url_id FROM url_aliases a WHERE a.alias_hash IN (/*SLICE:alias_hashes*/?)
+) r
+LEFT JOIN view_counts vc ON vc.url_id = r.url_id
+LEFT JOIN like_counts lc ON lc.url_id = r.url_id
+LEFT JOIN count_offsets o ON o.url_id = r.url_id
 `
 
+type BulkCountsByUrlsParams struct {
+	UrlHashes   [][]byte `json:"url_hashes"`
+	AliasHashes [][]byte `json:"alias_hashes"`
+}
+
 type BulkCountsByUrlsRow struct {
 	Url       string `json:"url"`
 	ViewCount int64  `json:"view_count"`
 	LikeCount int64  `json:"like_count"`
 }
 
-func (q *Queries) BulkCountsByUrls(ctx context.Context, urls []string) ([]BulkCountsByUrlsRow, error) {
+func (q *Queries) BulkCountsByUrls(ctx context.Context, arg BulkCountsByUrlsParams) ([]BulkCountsByUrlsRow, error) {
 	query := bulkCountsByUrls
 	var queryParams []interface{}
-	if len(urls) > 0 {
-		for _, v := range urls {
+	if len(arg.UrlHashes) > 0 {
+		for _, v := range arg.UrlHashes {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:url_hashes*/?", strings.Repeat(",?", len(arg.UrlHashes))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:url_hashes*/?", "NULL", 1)
+	}
+	if len(arg.AliasHashes) > 0 {
+		for _, v := range arg.AliasHashes {
 			queryParams = append(queryParams, v)
 		}
-		query = strings.Replace(query, "/*SLICE:urls*/?", strings.Repeat(",?", len(urls))[1:], 1)
+		query = strings.Replace(query, "/*SLICE:alias_hashes*/?", strings.Repeat(",?", len(arg.AliasHashes))[1:], 1)
 	} else {
-		query = strings.Replace(query, "/*SLICE:urls*/?", "NULL", 1)
+		query = strings.Replace(query, "/*SLICE:alias_hashes*/?", "NULL", 1)
 	}
 	rows, err := q.db.QueryContext(ctx, query, queryParams...)
 	if err != nil {
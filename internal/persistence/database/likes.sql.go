@@ -9,6 +9,30 @@ import (
 	"context"
 )
 
+const likeCountAdd = `-- name: LikeCountAdd :exec
+UPDATE like_counts SET count = count + ?, updated_at = ? WHERE url_id = ?
+`
+
+type LikeCountAddParams struct {
+	Delta     int64 `json:"delta"`
+	UpdatedAt int64 `json:"updated_at"`
+	UrlID     int64 `json:"url_id"`
+}
+
+func (q *Queries) LikeCountAdd(ctx context.Context, arg LikeCountAddParams) error {
+	_, err := q.db.ExecContext(ctx, likeCountAdd, arg.Delta, arg.UpdatedAt, arg.UrlID)
+	return err
+}
+
+const likeCountDelete = `-- name: LikeCountDelete :exec
+DELETE FROM like_counts WHERE url_id = ?
+`
+
+func (q *Queries) LikeCountDelete(ctx context.Context, urlID int64) error {
+	_, err := q.db.ExecContext(ctx, likeCountDelete, urlID)
+	return err
+}
+
 const likeCountInsert = `-- name: LikeCountInsert :exec
 INSERT INTO like_counts (id, url_id, count, updated_at)
 VALUES (?, ?, 1, ?)
@@ -41,6 +65,21 @@ func (q *Queries) LikeCountLookup(ctx context.Context, urlID int64) (LikeCount,
 	return i, err
 }
 
+const likeCountRepoint = `-- name: LikeCountRepoint :exec
+UPDATE like_counts SET url_id = ?, updated_at = ? WHERE url_id = ?
+`
+
+type LikeCountRepointParams struct {
+	IntoID    int64 `json:"into_id"`
+	UpdatedAt int64 `json:"updated_at"`
+	FromID    int64 `json:"from_id"`
+}
+
+func (q *Queries) LikeCountRepoint(ctx context.Context, arg LikeCountRepointParams) error {
+	_, err := q.db.ExecContext(ctx, likeCountRepoint, arg.IntoID, arg.UpdatedAt, arg.FromID)
+	return err
+}
+
 const likeCountUpdate = `-- name: LikeCountUpdate :exec
 UPDATE like_counts SET count = count + 1, updated_at = ? WHERE url_id = ?
 `
@@ -4,6 +4,47 @@
 
 package database
 
+type ApiKey struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	KeyHash    []byte `json:"key_hash"`
+	Scopes     string `json:"scopes"`
+	CreatedAt  int64  `json:"created_at"`
+	LastUsedAt int64  `json:"last_used_at"`
+	RevokedAt  int64  `json:"revoked_at"`
+}
+
+type AuditEvent struct {
+	ID          int64  `json:"id"`
+	ActorKeyID  int64  `json:"actor_key_id"`
+	Action      string `json:"action"`
+	Target      string `json:"target"`
+	BeforeValue string `json:"before_value"`
+	AfterValue  string `json:"after_value"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+type Blocklist struct {
+	ID        int64  `json:"id"`
+	Kind      int32  `json:"kind"`
+	Value     string `json:"value"`
+	Reason    string `json:"reason"`
+	CreatedBy int64  `json:"created_by"`
+	CreatedAt int64  `json:"created_at"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+type ClientDevice struct {
+	ID             int64  `json:"id"`
+	ClientID       int64  `json:"client_id"`
+	Browser        string `json:"browser"`
+	BrowserVersion string `json:"browser_version"`
+	Os             string `json:"os"`
+	DeviceClass    string `json:"device_class"`
+	Mobile         bool   `json:"mobile"`
+	UpdatedAt      int64  `json:"updated_at"`
+}
+
 type ClientFingerprint struct {
 	ID            int64  `json:"id"`
 	ClientID      int64  `json:"client_id"`
@@ -22,6 +63,57 @@ type ClientIdentifier struct {
 	CreatedAt int64  `json:"created_at"`
 }
 
+type CountOffset struct {
+	UrlID      int64 `json:"url_id"`
+	ViewOffset int64 `json:"view_offset"`
+	LikeOffset int64 `json:"like_offset"`
+	UpdatedAt  int64 `json:"updated_at"`
+}
+
+type ErrorGroup struct {
+	ID          int64  `json:"id"`
+	Fingerprint []byte `json:"fingerprint"`
+	Message     string `json:"message"`
+	Stack       string `json:"stack"`
+	Source      string `json:"source"`
+	Line        int32  `json:"line"`
+	Col         int32  `json:"col"`
+	UserAgent   string `json:"user_agent"`
+	Count       int64  `json:"count"`
+	FirstSeen   int64  `json:"first_seen"`
+	LastSeen    int64  `json:"last_seen"`
+}
+
+type ErrorGroupUrl struct {
+	GroupID  int64 `json:"group_id"`
+	UrlID    int64 `json:"url_id"`
+	Count    int64 `json:"count"`
+	LastSeen int64 `json:"last_seen"`
+}
+
+type Event struct {
+	ID         int64  `json:"id"`
+	UrlID      int64  `json:"url_id"`
+	ClientID   int64  `json:"client_id"`
+	Name       string `json:"name"`
+	Properties string `json:"properties"`
+	CreatedAt  int64  `json:"created_at"`
+	ReceivedAt int64  `json:"received_at"`
+}
+
+type EventName struct {
+	Site      string `json:"site"`
+	Name      string `json:"name"`
+	ClientID  int64  `json:"client_id"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type LeaderLease struct {
+	Name      string `json:"name"`
+	Holder    int64  `json:"holder"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
 type Like struct {
 	ID        int64 `json:"id"`
 	UrlID     int64 `json:"url_id"`
@@ -36,6 +128,13 @@ type LikeCount struct {
 	UpdatedAt int64 `json:"updated_at"`
 }
 
+type LivePresence struct {
+	UrlID       int64 `json:"url_id"`
+	NodeID      int64 `json:"node_id"`
+	Connections int32 `json:"connections"`
+	ExpiresAt   int64 `json:"expires_at"`
+}
+
 type RandflakeLease struct {
 	Uuid      []byte `json:"uuid"`
 	NodeID    int64  `json:"node_id"`
@@ -46,14 +145,46 @@ type RandflakeLease struct {
 type Url struct {
 	ID        int64  `json:"id"`
 	Url       string `json:"url"`
+	UrlHash   []byte `json:"url_hash"`
 	CreatedAt int64  `json:"created_at"`
 }
 
+type UrlAlias struct {
+	ID        int64  `json:"id"`
+	Alias     string `json:"alias"`
+	AliasHash []byte `json:"alias_hash"`
+	UrlID     int64  `json:"url_id"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+type UrlRanking struct {
+	Metric     int32   `json:"metric"`
+	TimeWindow int32   `json:"time_window"`
+	UrlID      int64   `json:"url_id"`
+	Score      float64 `json:"score"`
+	ComputedAt int64   `json:"computed_at"`
+}
+
+type UrlRelated struct {
+	UrlID        int64   `json:"url_id"`
+	RelatedUrlID int64   `json:"related_url_id"`
+	Clients      int64   `json:"clients"`
+	Score        float64 `json:"score"`
+	ComputedAt   int64   `json:"computed_at"`
+}
+
 type View struct {
-	ID        int64 `json:"id"`
-	UrlID     int64 `json:"url_id"`
-	ClientID  int64 `json:"client_id"`
-	CreatedAt int64 `json:"created_at"`
+	ID            int64  `json:"id"`
+	UrlID         int64  `json:"url_id"`
+	ClientID      int64  `json:"client_id"`
+	Referrer      string `json:"referrer"`
+	ReferrerClass string `json:"referrer_class"`
+	UtmSource     string `json:"utm_source"`
+	UtmMedium     string `json:"utm_medium"`
+	UtmCampaign   string `json:"utm_campaign"`
+	UtmTerm       string `json:"utm_term"`
+	UtmContent    string `json:"utm_content"`
+	CreatedAt     int64  `json:"created_at"`
 }
 
 type ViewCount struct {
@@ -62,3 +193,43 @@ type ViewCount struct {
 	Count     int64 `json:"count"`
 	UpdatedAt int64 `json:"updated_at"`
 }
+
+type ViewDailyRollup struct {
+	ID            int64 `json:"id"`
+	UrlID         int64 `json:"url_id"`
+	Day           int64 `json:"day"`
+	Views         int64 `json:"views"`
+	UniqueClients int64 `json:"unique_clients"`
+	CreatedAt     int64 `json:"created_at"`
+}
+
+type ViewEngagement struct {
+	ViewID    int64 `json:"view_id"`
+	UrlID     int64 `json:"url_id"`
+	ClientID  int64 `json:"client_id"`
+	ActiveMs  int64 `json:"active_ms"`
+	MaxScroll int32 `json:"max_scroll"`
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+type ViewIpDedup struct {
+	UrlID  int64  `json:"url_id"`
+	Day    int64  `json:"day"`
+	IpHash []byte `json:"ip_hash"`
+}
+
+type ViewRollupDay struct {
+	Day       int64 `json:"day"`
+	Views     int64 `json:"views"`
+	CreatedAt int64 `json:"created_at"`
+}
+
+type WebVitalBucket struct {
+	UrlID       int64  `json:"url_id"`
+	Day         int64  `json:"day"`
+	Metric      int32  `json:"metric"`
+	DeviceClass string `json:"device_class"`
+	Bucket      int16  `json:"bucket"`
+	Samples     int64  `json:"samples"`
+}
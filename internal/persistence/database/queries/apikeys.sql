@@ -0,0 +1,18 @@
+-- name: ApiKeyInsert :exec
+INSERT INTO api_keys (id, name, key_hash, scopes, created_at, last_used_at, revoked_at)
+VALUES (?, ?, ?, ?, ?, 0, 0);
+
+-- name: ApiKeyLookupByHash :one
+SELECT * FROM api_keys WHERE key_hash = ?;
+
+-- name: ApiKeyLookupByID :one
+SELECT * FROM api_keys WHERE id = ?;
+
+-- name: ApiKeyList :many
+SELECT * FROM api_keys ORDER BY created_at, id;
+
+-- name: ApiKeyRevoke :execrows
+UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at = 0;
+
+-- name: ApiKeyTouch :exec
+UPDATE api_keys SET last_used_at = sqlc.arg(now) WHERE id = sqlc.arg(id) AND last_used_at < sqlc.arg(before);
@@ -0,0 +1,14 @@
+-- name: AuditEventInsert :exec
+INSERT INTO audit_events (id, actor_key_id, action, target, before_value, after_value, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: AuditEventList :many
+SELECT * FROM audit_events
+WHERE created_at >= sqlc.arg(from_ts) AND created_at < sqlc.arg(to_ts)
+  AND (sqlc.arg(any_actor) OR actor_key_id = sqlc.arg(actor_key_id))
+  AND (sqlc.arg(action) = '' OR action = sqlc.arg(action))
+  AND (sqlc.arg(target) = '' OR target = sqlc.arg(target))
+  AND (created_at < sqlc.arg(cursor_created_at)
+    OR (created_at = sqlc.arg(cursor_created_at) AND id < sqlc.arg(cursor_id)))
+ORDER BY created_at DESC, id DESC
+LIMIT ?;
@@ -0,0 +1,29 @@
+-- name: BlocklistUpsert :exec
+INSERT INTO blocklist (id, kind, value, reason, created_by, created_at, expires_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+  reason = VALUES(reason),
+  created_by = VALUES(created_by),
+  created_at = VALUES(created_at),
+  expires_at = VALUES(expires_at);
+
+-- name: BlocklistLookup :one
+SELECT * FROM blocklist WHERE kind = ? AND value = ?;
+
+-- name: BlocklistLookupByID :one
+SELECT * FROM blocklist WHERE id = ?;
+
+-- name: BlocklistDelete :execrows
+DELETE FROM blocklist WHERE id = ?;
+
+-- name: BlocklistList :many
+SELECT * FROM blocklist ORDER BY created_at;
+
+-- name: BlocklistActive :many
+SELECT * FROM blocklist WHERE expires_at = 0 OR expires_at > ?;
+
+-- name: BlocklistDeleteExpired :execrows
+DELETE FROM blocklist WHERE expires_at <> 0 AND expires_at <= ?;
+
+-- name: BlocklistFingerprintClients :many
+SELECT DISTINCT client_id FROM client_fingerprints WHERE fphash IN (sqlc.slice('fphashes'));
@@ -1,9 +1,13 @@
 -- name: BulkCountsByUrls :many
 SELECT
-  u.url AS url,
-  COALESCE(vc.count, 0) AS view_count,
-  COALESCE(lc.count, 0) AS like_count
-FROM urls u
-LEFT JOIN view_counts vc ON vc.url_id = u.id
-LEFT JOIN like_counts lc ON lc.url_id = u.id
-WHERE u.url IN (sqlc.slice('urls'));
+  r.url AS url,
+  COALESCE(vc.count, 0) + COALESCE(o.view_offset, 0) AS view_count,
+  COALESCE(lc.count, 0) + COALESCE(o.like_offset, 0) AS like_count
+FROM (
+  SELECT u.url AS url, u.id AS url_id FROM urls u WHERE u.url_hash IN (sqlc.slice('url_hashes'))
+  UNION ALL
+  SELECT a.alias AS url, a.url_id AS url_id FROM url_aliases a WHERE a.alias_hash IN (sqlc.slice('alias_hashes'))
+) r
+LEFT JOIN view_counts vc ON vc.url_id = r.url_id
+LEFT JOIN like_counts lc ON lc.url_id = r.url_id
+LEFT JOIN count_offsets o ON o.url_id = r.url_id;
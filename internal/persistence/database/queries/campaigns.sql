@@ -0,0 +1,42 @@
+-- name: CampaignStats :many
+SELECT v.utm_source, v.utm_medium, v.utm_campaign, COUNT(*) AS count
+FROM views v
+JOIN urls u ON u.id = v.url_id
+WHERE (v.utm_source <> '' OR v.utm_campaign <> '')
+  AND v.created_at >= sqlc.arg(from_ts)
+  AND v.created_at < sqlc.arg(to_ts)
+  AND u.url LIKE sqlc.arg(url_pattern)
+GROUP BY v.utm_source, v.utm_medium, v.utm_campaign
+ORDER BY count DESC, v.utm_source, v.utm_medium, v.utm_campaign
+LIMIT ?;
+
+-- name: CampaignLandingPages :many
+SELECT utm_source, utm_medium, utm_campaign, url, count
+FROM (
+    SELECT v.utm_source, v.utm_medium, v.utm_campaign, u.url, COUNT(*) AS count,
+        ROW_NUMBER() OVER (
+            PARTITION BY v.utm_source, v.utm_medium, v.utm_campaign
+            ORDER BY COUNT(*) DESC, u.url
+        ) AS page_rank
+    FROM views v
+    JOIN urls u ON u.id = v.url_id
+    WHERE (v.utm_source <> '' OR v.utm_campaign <> '')
+      AND v.created_at >= sqlc.arg(from_ts)
+      AND v.created_at < sqlc.arg(to_ts)
+      AND u.url LIKE sqlc.arg(url_pattern)
+    GROUP BY v.utm_source, v.utm_medium, v.utm_campaign, u.url
+) ranked
+WHERE page_rank <= sqlc.arg(pages_per_campaign)
+ORDER BY count DESC, url;
+
+-- name: CampaignLandingPageTotals :many
+SELECT u.url, COUNT(*) AS count
+FROM views v
+JOIN urls u ON u.id = v.url_id
+WHERE (v.utm_source <> '' OR v.utm_campaign <> '')
+  AND v.created_at >= sqlc.arg(from_ts)
+  AND v.created_at < sqlc.arg(to_ts)
+  AND u.url LIKE sqlc.arg(url_pattern)
+GROUP BY u.url
+ORDER BY count DESC, u.url
+LIMIT ?;
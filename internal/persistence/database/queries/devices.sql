@@ -0,0 +1,26 @@
+-- name: ClientDeviceUpsert :exec
+INSERT INTO client_devices (id, client_id, browser, browser_version, os, device_class, mobile, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+  browser = VALUES(browser),
+  browser_version = VALUES(browser_version),
+  os = VALUES(os),
+  device_class = VALUES(device_class),
+  mobile = VALUES(mobile),
+  updated_at = VALUES(updated_at);
+
+-- name: ClientDeviceLookup :one
+SELECT * FROM client_devices WHERE client_id = ?;
+
+-- name: DeviceStatsByUrl :many
+SELECT
+  COALESCE(d.browser, 'Unknown') AS browser,
+  COALESCE(d.browser_version, '') AS browser_version,
+  COALESCE(d.os, 'Unknown') AS os,
+  COALESCE(d.device_class, 'unknown') AS device_class,
+  COALESCE(d.mobile, FALSE) AS mobile,
+  COUNT(*) AS count
+FROM views v
+LEFT JOIN client_devices d ON d.client_id = v.client_id
+WHERE v.url_id = ?
+GROUP BY browser, browser_version, os, device_class, mobile;
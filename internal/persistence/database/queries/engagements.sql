@@ -0,0 +1,32 @@
+-- name: EngagementViewLookup :one
+SELECT id, url_id, client_id, created_at FROM views WHERE id = ?;
+
+-- name: EngagementUpsert :exec
+INSERT INTO view_engagements (view_id, url_id, client_id, active_ms, max_scroll, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+  active_ms = GREATEST(active_ms, VALUES(active_ms)),
+  max_scroll = GREATEST(max_scroll, VALUES(max_scroll)),
+  updated_at = VALUES(updated_at);
+
+-- name: EngagementSummary :one
+SELECT
+  COUNT(*) AS views,
+  CAST(COALESCE(SUM(CASE WHEN max_scroll < 25 THEN 1 ELSE 0 END), 0) AS SIGNED) AS scroll_0,
+  CAST(COALESCE(SUM(CASE WHEN max_scroll >= 25 AND max_scroll < 50 THEN 1 ELSE 0 END), 0) AS SIGNED) AS scroll_25,
+  CAST(COALESCE(SUM(CASE WHEN max_scroll >= 50 AND max_scroll < 75 THEN 1 ELSE 0 END), 0) AS SIGNED) AS scroll_50,
+  CAST(COALESCE(SUM(CASE WHEN max_scroll >= 75 AND max_scroll < 100 THEN 1 ELSE 0 END), 0) AS SIGNED) AS scroll_75,
+  CAST(COALESCE(SUM(CASE WHEN max_scroll >= 100 THEN 1 ELSE 0 END), 0) AS SIGNED) AS scroll_100
+FROM view_engagements
+WHERE url_id = sqlc.arg(url_id)
+  AND created_at >= sqlc.arg(from_ts)
+  AND created_at < sqlc.arg(to_ts);
+
+-- name: EngagementActiveTimeAt :many
+SELECT active_ms
+FROM view_engagements
+WHERE url_id = sqlc.arg(url_id)
+  AND created_at >= sqlc.arg(from_ts)
+  AND created_at < sqlc.arg(to_ts)
+ORDER BY active_ms
+LIMIT 2 OFFSET ?;
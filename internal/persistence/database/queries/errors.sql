@@ -0,0 +1,36 @@
+-- name: ErrorGroupUpsert :exec
+INSERT INTO error_groups (id, fingerprint, message, stack, source, line, col, user_agent, count, first_seen, last_seen)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, 1, ?, ?)
+ON DUPLICATE KEY UPDATE
+  count = count + 1,
+  last_seen = GREATEST(last_seen, VALUES(last_seen));
+
+-- name: ErrorGroupIDByFingerprint :one
+SELECT id FROM error_groups WHERE fingerprint = ?;
+
+-- name: ErrorGroupUrlUpsert :exec
+INSERT INTO error_group_urls (group_id, url_id, count, last_seen)
+VALUES (?, ?, 1, ?)
+ON DUPLICATE KEY UPDATE
+  count = count + 1,
+  last_seen = GREATEST(last_seen, VALUES(last_seen));
+
+-- name: ErrorGroupList :many
+SELECT * FROM error_groups e
+WHERE e.last_seen >= sqlc.arg(from_ts)
+  AND e.last_seen < sqlc.arg(to_ts)
+  AND EXISTS (
+    SELECT 1 FROM error_group_urls g
+    JOIN urls u ON u.id = g.url_id
+    WHERE g.group_id = e.id AND u.url LIKE sqlc.arg(url_pattern)
+  )
+ORDER BY count DESC, last_seen DESC
+LIMIT ?;
+
+-- name: ErrorGroupUrlsByGroups :many
+SELECT g.group_id, u.url, g.count, g.last_seen
+FROM error_group_urls g
+JOIN urls u ON u.id = g.url_id
+WHERE g.group_id IN (sqlc.slice('group_ids'))
+  AND u.url LIKE sqlc.arg(url_pattern)
+ORDER BY g.group_id, g.count DESC;
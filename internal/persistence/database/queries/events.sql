@@ -0,0 +1,36 @@
+-- name: EventInsert :exec
+INSERT INTO events (id, url_id, client_id, name, properties, created_at, received_at)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: EventNameExists :one
+SELECT COUNT(*) FROM event_names WHERE site = ? AND name = ?;
+
+-- name: EventNameCountBySite :one
+SELECT COUNT(*) FROM event_names WHERE site = ?;
+
+-- name: EventNameCountByClient :one
+SELECT COUNT(*) FROM event_names WHERE client_id = ?;
+
+-- name: EventNameInsert :exec
+INSERT IGNORE INTO event_names (site, name, client_id, created_at) VALUES (?, ?, ?, ?);
+
+-- name: EventStatsByUrl :many
+SELECT name, COUNT(*) AS count, COUNT(DISTINCT client_id) AS clients
+FROM events
+WHERE url_id = sqlc.arg(url_id)
+  AND created_at >= sqlc.arg(from_ts)
+  AND created_at < sqlc.arg(to_ts)
+GROUP BY name
+ORDER BY count DESC, name
+LIMIT ?;
+
+-- name: EventStatsBySite :many
+SELECT e.name, COUNT(*) AS count, COUNT(DISTINCT e.client_id) AS clients, COUNT(DISTINCT e.url_id) AS urls
+FROM events e
+JOIN urls u ON u.id = e.url_id
+WHERE e.created_at >= sqlc.arg(from_ts)
+  AND e.created_at < sqlc.arg(to_ts)
+  AND u.url LIKE sqlc.arg(url_pattern)
+GROUP BY e.name
+ORDER BY count DESC, e.name
+LIMIT ?;
@@ -0,0 +1,12 @@
+-- name: LeaderLeaseAcquire :exec
+INSERT INTO leader_leases (name, holder, expires_at)
+VALUES (sqlc.arg(name), sqlc.arg(holder), sqlc.arg(expires_at))
+ON DUPLICATE KEY UPDATE
+  holder = IF(expires_at < sqlc.arg(now) OR holder = VALUES(holder), VALUES(holder), holder),
+  expires_at = IF(holder = VALUES(holder), VALUES(expires_at), expires_at);
+
+-- name: LeaderLeaseLookup :one
+SELECT * FROM leader_leases WHERE name = ?;
+
+-- name: LeaderLeaseRelease :exec
+DELETE FROM leader_leases WHERE name = ? AND holder = ?;
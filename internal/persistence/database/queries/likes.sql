@@ -10,4 +10,13 @@ VALUES (?, ?, 1, ?);
 SELECT id, url_id, count, updated_at FROM like_counts WHERE url_id = ?;
 
 -- name: LikeCountUpdate :exec
-UPDATE like_counts SET count = count + 1, updated_at = ? WHERE url_id = ?;
\ No newline at end of file
+UPDATE like_counts SET count = count + 1, updated_at = ? WHERE url_id = ?;
+
+-- name: LikeCountAdd :exec
+UPDATE like_counts SET count = count + sqlc.arg(delta), updated_at = sqlc.arg(updated_at) WHERE url_id = sqlc.arg(url_id);
+
+-- name: LikeCountRepoint :exec
+UPDATE like_counts SET url_id = sqlc.arg(into_id), updated_at = sqlc.arg(updated_at) WHERE url_id = sqlc.arg(from_id);
+
+-- name: LikeCountDelete :exec
+DELETE FROM like_counts WHERE url_id = ?;
@@ -0,0 +1,37 @@
+-- name: LiveSnapshot :one
+SELECT
+  CAST(
+    COALESCE((SELECT SUM(count) FROM view_counts WHERE url_id = sqlc.arg(url_id)), 0)
+    + COALESCE((SELECT view_offset FROM count_offsets WHERE url_id = sqlc.arg(url_id)), 0)
+  AS SIGNED) AS views,
+  CAST(
+    COALESCE((SELECT SUM(count) FROM like_counts WHERE url_id = sqlc.arg(url_id)), 0)
+    + COALESCE((SELECT like_offset FROM count_offsets WHERE url_id = sqlc.arg(url_id)), 0)
+  AS SIGNED) AS likes,
+  (SELECT COUNT(*) FROM (
+    SELECT client_id FROM views
+    WHERE url_id = sqlc.arg(url_id) AND created_at >= sqlc.arg(since) AND client_id <> 0
+    UNION
+    SELECT client_id FROM view_engagements
+    WHERE url_id = sqlc.arg(url_id) AND updated_at >= sqlc.arg(since)
+  ) active) AS readers,
+  CAST(COALESCE((
+    SELECT SUM(connections) FROM live_presence
+    WHERE url_id = sqlc.arg(url_id) AND expires_at > sqlc.arg(now)
+  ), 0) AS SIGNED) AS present;
+
+-- name: PresenceUpsert :exec
+INSERT INTO live_presence (url_id, node_id, connections, expires_at)
+VALUES (?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+  connections = VALUES(connections),
+  expires_at = VALUES(expires_at);
+
+-- name: PresenceDelete :exec
+DELETE FROM live_presence WHERE url_id = ? AND node_id = ?;
+
+-- name: PresenceDeleteNode :exec
+DELETE FROM live_presence WHERE node_id = ?;
+
+-- name: PresenceDeleteExpired :exec
+DELETE FROM live_presence WHERE expires_at <= ?;
@@ -0,0 +1,89 @@
+-- name: ViewPurge :execrows
+DELETE FROM views
+WHERE url_id = ? AND created_at >= sqlc.arg(from_ts) AND created_at < sqlc.arg(to_ts);
+
+-- name: ViewPurgeClients :execrows
+DELETE FROM views
+WHERE url_id = ? AND created_at >= sqlc.arg(from_ts) AND created_at < sqlc.arg(to_ts)
+  AND client_id IN (sqlc.slice('client_ids'));
+
+-- name: EngagementPurge :exec
+DELETE FROM view_engagements
+WHERE url_id = ? AND created_at >= sqlc.arg(from_ts) AND created_at < sqlc.arg(to_ts);
+
+-- name: EngagementPurgeClients :exec
+DELETE FROM view_engagements
+WHERE url_id = ? AND created_at >= sqlc.arg(from_ts) AND created_at < sqlc.arg(to_ts)
+  AND client_id IN (sqlc.slice('client_ids'));
+
+-- name: LikePurge :execrows
+DELETE FROM likes
+WHERE url_id = ? AND created_at >= sqlc.arg(from_ts) AND created_at < sqlc.arg(to_ts);
+
+-- name: LikePurgeClients :execrows
+DELETE FROM likes
+WHERE url_id = ? AND created_at >= sqlc.arg(from_ts) AND created_at < sqlc.arg(to_ts)
+  AND client_id IN (sqlc.slice('client_ids'));
+
+-- name: ViewRollupDaysOverlap :one
+SELECT COUNT(*) FROM view_rollup_days
+WHERE day > sqlc.arg(from_ts) - 86400000000000 AND day < sqlc.arg(to_ts);
+
+-- name: ViewRollupDayLatest :one
+SELECT COALESCE(MAX(day), -1) AS day FROM view_rollup_days;
+
+-- name: ViewCountFirstID :one
+SELECT id FROM view_counts WHERE url_id = ? ORDER BY id LIMIT 1;
+
+-- name: ViewCountDeleteOthers :exec
+DELETE FROM view_counts WHERE url_id = sqlc.arg(url_id) AND id <> sqlc.arg(id);
+
+-- name: ViewCountRecompute :exec
+UPDATE view_counts SET
+  count = (SELECT COUNT(*) FROM views v
+    WHERE v.url_id = sqlc.arg(url_id)
+      AND NOT EXISTS (
+        SELECT 1 FROM view_rollup_days d
+        WHERE d.day = (v.created_at DIV 86400000000000) * 86400000000000
+      ))
+    + (SELECT COALESCE(SUM(r.views), 0) FROM view_daily_rollups r WHERE r.url_id = sqlc.arg(url_id)),
+  updated_at = sqlc.arg(updated_at)
+WHERE view_counts.id = sqlc.arg(id);
+
+-- name: LikeCountFirstID :one
+SELECT id FROM like_counts WHERE url_id = ? ORDER BY id LIMIT 1;
+
+-- name: LikeCountDeleteOthers :exec
+DELETE FROM like_counts WHERE url_id = sqlc.arg(url_id) AND id <> sqlc.arg(id);
+
+-- name: LikeCountRecompute :exec
+UPDATE like_counts SET
+  count = (SELECT COUNT(*) FROM likes l WHERE l.url_id = sqlc.arg(url_id)),
+  updated_at = sqlc.arg(updated_at)
+WHERE like_counts.id = sqlc.arg(id);
+
+-- name: ViewCountDisplay :one
+SELECT CAST(
+  COALESCE((SELECT SUM(count) FROM view_counts WHERE url_id = sqlc.arg(url_id)), 0)
+  + COALESCE((SELECT view_offset FROM count_offsets WHERE url_id = sqlc.arg(url_id)), 0)
+AS SIGNED) AS count;
+
+-- name: LikeCountDisplay :one
+SELECT CAST(
+  COALESCE((SELECT SUM(count) FROM like_counts WHERE url_id = sqlc.arg(url_id)), 0)
+  + COALESCE((SELECT like_offset FROM count_offsets WHERE url_id = sqlc.arg(url_id)), 0)
+AS SIGNED) AS count;
+
+-- name: CountOffsetLookup :one
+SELECT * FROM count_offsets WHERE url_id = ?;
+
+-- name: CountOffsetUpsert :exec
+INSERT INTO count_offsets (url_id, view_offset, like_offset, updated_at)
+VALUES (?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+  view_offset = VALUES(view_offset),
+  like_offset = VALUES(like_offset),
+  updated_at = VALUES(updated_at);
+
+-- name: CountOffsetDelete :exec
+DELETE FROM count_offsets WHERE url_id = ?;
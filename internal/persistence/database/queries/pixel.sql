@@ -0,0 +1,5 @@
+-- name: ViewIPDedupInsert :execrows
+INSERT IGNORE INTO view_ip_dedup (url_id, day, ip_hash) VALUES (?, ?, ?);
+
+-- name: ViewIPDedupDeleteBefore :execrows
+DELETE FROM view_ip_dedup WHERE day < ? LIMIT ?;
@@ -0,0 +1,81 @@
+-- name: RankingViewsSince :many
+SELECT c.url_id, u.url, CAST(SUM(c.views) AS SIGNED) AS count
+FROM (
+  SELECT v.url_id, COUNT(*) AS views
+  FROM views v
+  WHERE v.created_at >= sqlc.arg(from_ts)
+    AND NOT EXISTS (
+      SELECT 1 FROM view_rollup_days d
+      WHERE d.day = (v.created_at DIV 86400000000000) * 86400000000000
+    )
+  GROUP BY v.url_id
+  UNION ALL
+  SELECT r.url_id, SUM(r.views) AS views
+  FROM view_daily_rollups r
+  WHERE r.day >= sqlc.arg(from_ts)
+  GROUP BY r.url_id
+) c
+JOIN urls u ON u.id = c.url_id
+GROUP BY c.url_id, u.url;
+
+-- name: RankingLikesSince :many
+SELECT l.url_id, u.url, COUNT(*) AS count
+FROM likes l
+JOIN urls u ON u.id = l.url_id
+WHERE l.created_at >= ?
+GROUP BY l.url_id, u.url;
+
+-- name: RankingViewsAllTime :many
+SELECT c.url_id, u.url, c.count
+FROM view_counts c
+JOIN urls u ON u.id = c.url_id
+WHERE c.count > 0;
+
+-- name: RankingLikesAllTime :many
+SELECT c.url_id, u.url, c.count
+FROM like_counts c
+JOIN urls u ON u.id = c.url_id
+WHERE c.count > 0;
+
+-- name: RankingViewsDecayed :many
+SELECT c.url_id, u.url, CAST(SUM(c.score) AS DOUBLE) AS score
+FROM (
+  SELECT v.url_id, SUM(EXP((v.created_at - sqlc.arg(now)) / sqlc.arg(tau))) AS score
+  FROM views v
+  WHERE v.created_at >= sqlc.arg(from_ts)
+    AND NOT EXISTS (
+      SELECT 1 FROM view_rollup_days d
+      WHERE d.day = (v.created_at DIV 86400000000000) * 86400000000000
+    )
+  GROUP BY v.url_id
+  UNION ALL
+  SELECT r.url_id, SUM(r.views * EXP((r.day + 43200000000000 - sqlc.arg(now)) / sqlc.arg(tau))) AS score
+  FROM view_daily_rollups r
+  WHERE r.day >= sqlc.arg(from_ts)
+  GROUP BY r.url_id
+) c
+JOIN urls u ON u.id = c.url_id
+GROUP BY c.url_id, u.url;
+
+-- name: RankingLikesDecayed :many
+SELECT l.url_id, u.url, CAST(SUM(EXP((l.created_at - sqlc.arg(now)) / sqlc.arg(tau))) AS DOUBLE) AS score
+FROM likes l
+JOIN urls u ON u.id = l.url_id
+WHERE l.created_at >= sqlc.arg(from_ts)
+GROUP BY l.url_id, u.url;
+
+-- name: RankingDelete :exec
+DELETE FROM url_rankings WHERE metric = ? AND time_window = ?;
+
+-- name: RankingInsert :exec
+INSERT INTO url_rankings (metric, time_window, url_id, score, computed_at) VALUES (?, ?, ?, ?, ?);
+
+-- name: RankingTop :many
+SELECT u.url, r.score, r.computed_at
+FROM url_rankings r
+JOIN urls u ON u.id = r.url_id
+WHERE r.metric = sqlc.arg(metric)
+  AND r.time_window = sqlc.arg(time_window)
+  AND u.url LIKE sqlc.arg(url_pattern)
+ORDER BY r.score DESC, r.url_id
+LIMIT ?;
@@ -0,0 +1,36 @@
+-- name: CountReconcileBatch :many
+SELECT
+  u.id AS url_id,
+  u.url AS url,
+  COALESCE(vc.count, 0) AS view_count,
+  (SELECT COUNT(*) FROM views v
+    WHERE v.url_id = u.id
+      AND NOT EXISTS (
+        SELECT 1 FROM view_rollup_days d
+        WHERE d.day = (v.created_at DIV 86400000000000) * 86400000000000
+      ))
+    + (SELECT COALESCE(SUM(r.views), 0) FROM view_daily_rollups r WHERE r.url_id = u.id) AS view_rows,
+  COALESCE(lc.count, 0) AS like_count,
+  (SELECT COUNT(*) FROM likes l WHERE l.url_id = u.id) AS like_rows
+FROM urls u
+LEFT JOIN view_counts vc ON vc.url_id = u.id
+LEFT JOIN like_counts lc ON lc.url_id = u.id
+WHERE u.id > sqlc.arg(after_id)
+ORDER BY u.id
+LIMIT ?;
+
+-- name: ViewCountReconcile :execrows
+UPDATE view_counts SET count = sqlc.arg(count), updated_at = sqlc.arg(updated_at)
+WHERE url_id = sqlc.arg(url_id) AND count = sqlc.arg(expected);
+
+-- name: ViewCountCreate :exec
+INSERT INTO view_counts (id, url_id, count, updated_at)
+VALUES (?, ?, ?, ?);
+
+-- name: LikeCountReconcile :execrows
+UPDATE like_counts SET count = sqlc.arg(count), updated_at = sqlc.arg(updated_at)
+WHERE url_id = sqlc.arg(url_id) AND count = sqlc.arg(expected);
+
+-- name: LikeCountCreate :exec
+INSERT INTO like_counts (id, url_id, count, updated_at)
+VALUES (?, ?, ?, ?);
@@ -0,0 +1,9 @@
+-- name: ReferrerStatsByUrl :many
+SELECT referrer, referrer_class, COUNT(*) AS count
+FROM views
+WHERE url_id = sqlc.arg(url_id)
+  AND created_at >= sqlc.arg(from_ts)
+  AND created_at < sqlc.arg(to_ts)
+GROUP BY referrer, referrer_class
+ORDER BY count DESC
+LIMIT ?;
@@ -0,0 +1,31 @@
+-- name: RelatedClientBatch :many
+SELECT DISTINCT client_id
+FROM views
+WHERE client_id > sqlc.arg(after_client_id)
+  AND created_at >= sqlc.arg(from_ts)
+ORDER BY client_id
+LIMIT ?;
+
+-- name: RelatedVisitsByClients :many
+SELECT client_id, url_id
+FROM views
+WHERE client_id > sqlc.arg(after_client_id)
+  AND client_id <= sqlc.arg(last_client_id)
+  AND created_at >= sqlc.arg(from_ts)
+GROUP BY client_id, url_id
+ORDER BY client_id, MAX(created_at) DESC;
+
+-- name: RelatedDeleteAll :exec
+DELETE FROM url_related;
+
+-- name: RelatedInsert :exec
+INSERT INTO url_related (url_id, related_url_id, clients, score, computed_at) VALUES (?, ?, ?, ?, ?);
+
+-- name: RelatedByUrl :many
+SELECT u.url, r.score
+FROM url_related r
+JOIN urls u ON u.id = r.related_url_id
+WHERE r.url_id = sqlc.arg(url_id)
+  AND u.url LIKE sqlc.arg(url_pattern)
+ORDER BY r.score DESC, r.related_url_id
+LIMIT ?;
@@ -0,0 +1,23 @@
+-- name: ViewOldestBefore :one
+SELECT COALESCE(MIN(created_at), 0) AS oldest FROM views WHERE created_at < ?;
+
+-- name: ViewDailyAggregate :many
+SELECT url_id, COUNT(*) AS views,
+    COUNT(DISTINCT NULLIF(client_id, 0)) + COUNT(CASE WHEN client_id = 0 THEN 1 END) AS unique_clients
+FROM views
+WHERE created_at >= sqlc.arg(from_ts) AND created_at < sqlc.arg(to_ts)
+GROUP BY url_id;
+
+-- name: ViewDeleteBefore :execrows
+DELETE FROM views WHERE created_at < ? ORDER BY created_at LIMIT ?;
+
+-- name: ViewRollupInsert :exec
+INSERT INTO view_daily_rollups (id, url_id, day, views, unique_clients, created_at)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: ViewRollupDayLookup :one
+SELECT * FROM view_rollup_days WHERE day = ?;
+
+-- name: ViewRollupDayInsert :exec
+INSERT INTO view_rollup_days (day, views, created_at)
+VALUES (?, ?, ?);
@@ -0,0 +1,14 @@
+-- name: ViewDailyCountsByUrl :many
+SELECT CAST(created_at DIV 86400000000000 AS SIGNED) AS day_index, COUNT(*) AS views
+FROM views
+WHERE url_id = sqlc.arg(url_id)
+  AND created_at >= sqlc.arg(from_ts)
+  AND created_at < sqlc.arg(to_ts)
+GROUP BY day_index;
+
+-- name: ViewRollupsByUrl :many
+SELECT day, views
+FROM view_daily_rollups
+WHERE url_id = sqlc.arg(url_id)
+  AND day >= sqlc.arg(from_ts)
+  AND day < sqlc.arg(to_ts);
@@ -0,0 +1,116 @@
+-- name: UrlLookupByID :one
+SELECT * FROM urls WHERE id = ?;
+
+-- name: UrlDelete :exec
+DELETE FROM urls WHERE id = ?;
+
+-- name: UrlListDuplicates :many
+SELECT url, COUNT(*) AS count FROM urls GROUP BY url HAVING COUNT(*) > 1;
+
+-- name: UrlListByUrl :many
+SELECT * FROM urls WHERE url = ? ORDER BY created_at, id;
+
+-- name: UrlBackfillHashes :execrows
+UPDATE urls SET url_hash = UNHEX(SHA2(url, 256))
+WHERE url_hash IS NULL OR url_hash <> UNHEX(SHA2(url, 256));
+
+-- name: UrlAliasLookup :one
+SELECT * FROM url_aliases WHERE alias_hash = ?;
+
+-- name: UrlAliasInsert :exec
+INSERT INTO url_aliases (id, alias, alias_hash, url_id, created_at)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: UrlAliasListByUrlID :many
+SELECT * FROM url_aliases WHERE url_id = ? ORDER BY created_at;
+
+-- name: UrlAliasRepoint :exec
+UPDATE url_aliases SET url_id = sqlc.arg(into_id) WHERE url_id = sqlc.arg(from_id);
+
+-- name: UrlAliasBackfillHashes :execrows
+UPDATE url_aliases SET alias_hash = UNHEX(SHA2(alias, 256))
+WHERE alias_hash IS NULL OR alias_hash <> UNHEX(SHA2(alias, 256));
+
+-- name: UrlMergeDeleteDuplicateLikes :execrows
+DELETE l FROM likes l
+JOIN likes k ON k.client_id = l.client_id AND k.url_id = sqlc.arg(into_id)
+WHERE l.url_id = sqlc.arg(from_id);
+
+-- name: UrlMergeViews :execrows
+UPDATE views SET url_id = sqlc.arg(into_id) WHERE url_id = sqlc.arg(from_id);
+
+-- name: UrlMergeLikes :execrows
+UPDATE likes SET url_id = sqlc.arg(into_id) WHERE url_id = sqlc.arg(from_id);
+
+-- name: UrlMergeRollupsAdd :exec
+UPDATE view_daily_rollups i
+JOIN view_daily_rollups f ON f.day = i.day AND f.url_id = sqlc.arg(from_id)
+SET i.views = i.views + f.views, i.unique_clients = i.unique_clients + f.unique_clients
+WHERE i.url_id = sqlc.arg(into_id);
+
+-- name: UrlMergeRollupsDeleteOverlap :exec
+DELETE f FROM view_daily_rollups f
+JOIN view_daily_rollups i ON i.day = f.day AND i.url_id = sqlc.arg(into_id)
+WHERE f.url_id = sqlc.arg(from_id);
+
+-- name: UrlMergeRollups :exec
+UPDATE view_daily_rollups SET url_id = sqlc.arg(into_id) WHERE url_id = sqlc.arg(from_id);
+
+-- name: UrlMergeEvents :exec
+UPDATE events SET url_id = sqlc.arg(into_id) WHERE url_id = sqlc.arg(from_id);
+
+-- name: UrlMergeEngagements :exec
+UPDATE view_engagements SET url_id = sqlc.arg(into_id) WHERE url_id = sqlc.arg(from_id);
+
+-- name: UrlMergeVitalsAdd :exec
+UPDATE web_vital_buckets i
+JOIN web_vital_buckets f ON f.day = i.day AND f.metric = i.metric AND f.device_class = i.device_class
+  AND f.bucket = i.bucket AND f.url_id = sqlc.arg(from_id)
+SET i.samples = i.samples + f.samples
+WHERE i.url_id = sqlc.arg(into_id);
+
+-- name: UrlMergeVitalsDeleteOverlap :exec
+DELETE f FROM web_vital_buckets f
+JOIN web_vital_buckets i ON i.day = f.day AND i.metric = f.metric AND i.device_class = f.device_class
+  AND i.bucket = f.bucket AND i.url_id = sqlc.arg(into_id)
+WHERE f.url_id = sqlc.arg(from_id);
+
+-- name: UrlMergeVitals :exec
+UPDATE web_vital_buckets SET url_id = sqlc.arg(into_id) WHERE url_id = sqlc.arg(from_id);
+
+-- name: UrlMergeErrorUrlsAdd :exec
+UPDATE error_group_urls i
+JOIN error_group_urls f ON f.group_id = i.group_id AND f.url_id = sqlc.arg(from_id)
+SET i.count = i.count + f.count, i.last_seen = GREATEST(i.last_seen, f.last_seen)
+WHERE i.url_id = sqlc.arg(into_id);
+
+-- name: UrlMergeErrorUrlsDeleteOverlap :exec
+DELETE f FROM error_group_urls f
+JOIN error_group_urls i ON i.group_id = f.group_id AND i.url_id = sqlc.arg(into_id)
+WHERE f.url_id = sqlc.arg(from_id);
+
+-- name: UrlMergeErrorUrls :exec
+UPDATE error_group_urls SET url_id = sqlc.arg(into_id) WHERE url_id = sqlc.arg(from_id);
+
+-- name: UrlMergeIPDedupDelete :exec
+DELETE FROM view_ip_dedup WHERE url_id = ?;
+
+-- name: UrlMergeRankingsDelete :exec
+DELETE FROM url_rankings WHERE url_id = ?;
+
+-- name: UrlMergeRelatedDelete :exec
+DELETE FROM url_related WHERE url_id = ? OR related_url_id = ?;
+
+-- name: UrlMergeCountOffsetsAdd :exec
+UPDATE count_offsets i
+JOIN count_offsets f ON f.url_id = sqlc.arg(from_id)
+SET i.view_offset = i.view_offset + f.view_offset, i.like_offset = i.like_offset + f.like_offset
+WHERE i.url_id = sqlc.arg(into_id);
+
+-- name: UrlMergeCountOffsetsDeleteOverlap :exec
+DELETE f FROM count_offsets f
+JOIN count_offsets i ON i.url_id = sqlc.arg(into_id)
+WHERE f.url_id = sqlc.arg(from_id);
+
+-- name: UrlMergeCountOffsets :exec
+UPDATE count_offsets SET url_id = sqlc.arg(into_id) WHERE url_id = sqlc.arg(from_id);
@@ -1,6 +1,6 @@
 -- name: ViewInsert :exec
-INSERT INTO views (id, url_id, client_id, created_at)
-VALUES (?, ?, ?, ?);
+INSERT INTO views (id, url_id, client_id, referrer, referrer_class, utm_source, utm_medium, utm_campaign, utm_term, utm_content, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 
 -- name: ViewCountLookup :one
 SELECT * FROM view_counts WHERE url_id = ?;
@@ -12,9 +12,18 @@ VALUES (?, ?, 1, ?);
 -- name: ViewCountUpdate :exec
 UPDATE view_counts SET count = count + 1, updated_at = ? WHERE url_id = ?;
 
--- name: UrlLookupByUrl :one
-SELECT * FROM urls WHERE url = ?;
+-- name: ViewCountAdd :exec
+UPDATE view_counts SET count = count + sqlc.arg(delta), updated_at = sqlc.arg(updated_at) WHERE url_id = sqlc.arg(url_id);
+
+-- name: ViewCountRepoint :exec
+UPDATE view_counts SET url_id = sqlc.arg(into_id), updated_at = sqlc.arg(updated_at) WHERE url_id = sqlc.arg(from_id);
+
+-- name: ViewCountDelete :exec
+DELETE FROM view_counts WHERE url_id = ?;
 
--- name: UrlInsert :exec
-INSERT INTO urls (id, url, created_at)
-VALUES (?, ?, ?);
+-- name: UrlLookupByHash :one
+SELECT * FROM urls WHERE url_hash = ?;
+
+-- name: UrlInsert :execrows
+INSERT IGNORE INTO urls (id, url, url_hash, created_at)
+VALUES (?, ?, ?, ?);
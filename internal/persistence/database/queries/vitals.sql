@@ -0,0 +1,12 @@
+-- name: VitalBucketAdd :exec
+INSERT INTO web_vital_buckets (url_id, day, metric, device_class, bucket, samples)
+VALUES (?, ?, ?, ?, ?, 1)
+ON DUPLICATE KEY UPDATE samples = samples + 1;
+
+-- name: VitalBucketsByUrl :many
+SELECT metric, device_class, bucket, CAST(SUM(samples) AS SIGNED) AS samples
+FROM web_vital_buckets
+WHERE url_id = sqlc.arg(url_id)
+  AND day >= sqlc.arg(from_day)
+  AND day < sqlc.arg(to_ts)
+GROUP BY metric, device_class, bucket;
@@ -4,10 +4,35 @@ CREATE TABLE views
     url_id BIGINT NOT NULL,
     client_id BIGINT NOT NULL,
 
+    referrer VARCHAR(255) NOT NULL DEFAULT '',
+    referrer_class VARCHAR(16) NOT NULL DEFAULT '',
+
+    utm_source VARCHAR(255) NOT NULL DEFAULT '',
+    utm_medium VARCHAR(255) NOT NULL DEFAULT '',
+    utm_campaign VARCHAR(255) NOT NULL DEFAULT '',
+    utm_term VARCHAR(255) NOT NULL DEFAULT '',
+    utm_content VARCHAR(255) NOT NULL DEFAULT '',
+
     created_at BIGINT NOT NULL
 ) ENGINE = InnoDB;
 
 CREATE INDEX views_url_id_idx ON views(url_id);
+CREATE INDEX views_url_id_created_at_idx ON views(url_id, created_at);
+CREATE INDEX views_utm_campaign_created_at_idx ON views(utm_campaign, created_at);
+CREATE INDEX views_created_at_idx ON views(created_at);
+CREATE INDEX views_client_id_created_at_idx ON views(client_id, created_at);
+
+-- Keyed hashes of the IP addresses that viewed a URL through the tracking pixel on a UTC day
+CREATE TABLE view_ip_dedup
+(
+    url_id BIGINT NOT NULL,
+    day BIGINT NOT NULL, -- start of the UTC day in unix nanoseconds
+    ip_hash BINARY(32) NOT NULL,
+
+    PRIMARY KEY (url_id, day, ip_hash)
+) ENGINE = InnoDB;
+
+CREATE INDEX view_ip_dedup_day_idx ON view_ip_dedup(day);
 
 CREATE TABLE view_counts
 (
@@ -20,6 +45,93 @@ CREATE TABLE view_counts
 
 CREATE INDEX view_counts_url_id_idx ON view_counts(url_id);
 
+-- Reading time and scroll depth reported by clients for a single view
+CREATE TABLE view_engagements
+(
+    view_id BIGINT PRIMARY KEY,
+    url_id BIGINT NOT NULL,
+    client_id BIGINT NOT NULL,
+    active_ms BIGINT NOT NULL, -- cumulative active reading time
+    max_scroll INT NOT NULL, -- maximum scroll depth in percent
+
+    created_at BIGINT NOT NULL, -- creation time of the view
+    updated_at BIGINT NOT NULL
+) ENGINE = InnoDB;
+
+CREATE INDEX view_engagements_url_id_created_at_idx ON view_engagements(url_id, created_at);
+CREATE INDEX view_engagements_url_id_updated_at_idx ON view_engagements(url_id, updated_at);
+
+-- Core Web Vitals measurements, one row per metric of a page load
+-- Web vitals counted per URL, UTC day, metric and device class of the reporting client
+-- in logarithmic value buckets, see core.VitalBucket
+CREATE TABLE web_vital_buckets
+(
+    url_id BIGINT NOT NULL,
+    day BIGINT NOT NULL, -- start of the UTC day in unix nanoseconds
+    metric TINYINT NOT NULL, -- 1 LCP, 2 INP, 3 CLS, 4 FCP, 5 TTFB
+    device_class VARCHAR(16) NOT NULL,
+    bucket SMALLINT NOT NULL,
+    samples BIGINT NOT NULL,
+
+    PRIMARY KEY (url_id, day, metric, device_class, bucket)
+) ENGINE = InnoDB;
+
+-- Client-side JavaScript errors grouped by the fingerprint of their normalized stack
+CREATE TABLE error_groups
+(
+    id BIGINT PRIMARY KEY,
+    fingerprint BINARY(32) NOT NULL,
+    message VARCHAR(1024) NOT NULL, -- of the first report
+    stack TEXT NOT NULL, -- of the first report
+    source VARCHAR(1024) NOT NULL, -- script URL of the first report
+    line INT NOT NULL,
+    col INT NOT NULL,
+    user_agent VARCHAR(512) NOT NULL, -- of the first report
+    count BIGINT NOT NULL,
+
+    first_seen BIGINT NOT NULL,
+    last_seen BIGINT NOT NULL
+) ENGINE = InnoDB;
+
+CREATE UNIQUE INDEX error_groups_fingerprint_idx ON error_groups(fingerprint);
+CREATE INDEX error_groups_last_seen_idx ON error_groups(last_seen);
+
+-- Pages an error group was reported on
+CREATE TABLE error_group_urls
+(
+    group_id BIGINT NOT NULL,
+    url_id BIGINT NOT NULL,
+    count BIGINT NOT NULL,
+    last_seen BIGINT NOT NULL,
+
+    PRIMARY KEY (group_id, url_id)
+) ENGINE = InnoDB;
+
+CREATE INDEX error_group_urls_url_id_idx ON error_group_urls(url_id);
+
+-- Daily aggregates of raw views removed by the retention worker
+CREATE TABLE view_daily_rollups
+(
+    id BIGINT PRIMARY KEY,
+    url_id BIGINT NOT NULL,
+    day BIGINT NOT NULL, -- start of the UTC day in unix nanoseconds
+    views BIGINT NOT NULL,
+    unique_clients BIGINT NOT NULL,
+
+    created_at BIGINT NOT NULL
+) ENGINE = InnoDB;
+
+CREATE UNIQUE INDEX view_daily_rollups_url_id_day_idx ON view_daily_rollups(url_id, day);
+
+-- Days whose raw views have been rolled up into view_daily_rollups
+CREATE TABLE view_rollup_days
+(
+    day BIGINT PRIMARY KEY,
+    views BIGINT NOT NULL,
+
+    created_at BIGINT NOT NULL
+) ENGINE = InnoDB;
+
 CREATE TABLE likes
 (
     id BIGINT PRIMARY KEY,
@@ -43,6 +155,35 @@ CREATE TABLE like_counts
 
 CREATE INDEX like_counts_url_id_idx ON like_counts(url_id);
 
+-- Custom events sent by clients, e.g. through POST /client/events
+CREATE TABLE events
+(
+    id BIGINT PRIMARY KEY,
+    url_id BIGINT NOT NULL,
+    client_id BIGINT NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    properties TEXT NOT NULL, -- JSON object
+
+    created_at BIGINT NOT NULL, -- client timestamp
+    received_at BIGINT NOT NULL
+) ENGINE = InnoDB;
+
+CREATE INDEX events_url_id_created_at_idx ON events(url_id, created_at);
+CREATE INDEX events_name_created_at_idx ON events(name, created_at);
+
+-- Distinct custom event names per site (host of the normalized URL), used to cap their cardinality
+CREATE TABLE event_names
+(
+    site VARCHAR(255) NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    client_id BIGINT NOT NULL, -- client that first used the name
+    created_at BIGINT NOT NULL,
+
+    PRIMARY KEY (site, name)
+) ENGINE = InnoDB;
+
+CREATE INDEX event_names_client_id_idx ON event_names(client_id);
+
 CREATE TABLE client_identifiers
 (
     id BIGINT PRIMARY KEY,
@@ -71,15 +212,46 @@ CREATE TABLE client_fingerprints
 CREATE INDEX client_fingerprints_client_id_idx ON client_fingerprints(client_id);
 CREATE INDEX client_fingerprints_fphash_idx ON client_fingerprints(fphash);
 
+CREATE TABLE client_devices
+(
+    id BIGINT PRIMARY KEY,
+    client_id BIGINT NOT NULL,
+
+    browser VARCHAR(64) NOT NULL,
+    browser_version VARCHAR(16) NOT NULL,
+    os VARCHAR(64) NOT NULL,
+    device_class VARCHAR(16) NOT NULL,
+    mobile BOOLEAN NOT NULL,
+
+    updated_at BIGINT NOT NULL
+) ENGINE = InnoDB;
+
+CREATE UNIQUE INDEX client_devices_client_id_idx ON client_devices(client_id);
+
 CREATE TABLE urls
 (
     id BIGINT PRIMARY KEY,
     url TEXT NOT NULL,
+    url_hash BINARY(32) NOT NULL,
 
     created_at BIGINT NOT NULL
 ) ENGINE = InnoDB;
 
 CREATE INDEX urls_id_idx ON urls(id);
+CREATE UNIQUE INDEX urls_url_hash_idx ON urls(url_hash);
+
+CREATE TABLE url_aliases
+(
+    id BIGINT PRIMARY KEY,
+    alias TEXT NOT NULL,
+    alias_hash BINARY(32) NOT NULL,
+    url_id BIGINT NOT NULL,
+
+    created_at BIGINT NOT NULL
+) ENGINE = InnoDB;
+
+CREATE UNIQUE INDEX url_aliases_alias_hash_idx ON url_aliases(alias_hash);
+CREATE INDEX url_aliases_url_id_idx ON url_aliases(url_id);
 
 CREATE TABLE randflake_leases
 (
@@ -91,3 +263,113 @@ CREATE TABLE randflake_leases
 
 CREATE UNIQUE INDEX randflake_leases_node_id_idx ON randflake_leases(node_id);
 CREATE INDEX randflake_leases_expires_at_idx ON randflake_leases(expires_at ASC);
+
+-- Database backed leader election for background jobs that must run on a single node
+CREATE TABLE leader_leases
+(
+    name VARCHAR(64) PRIMARY KEY,
+    holder BIGINT NOT NULL,
+    expires_at BIGINT NOT NULL
+) ENGINE = InnoDB;
+
+-- Leaderboards rebuilt periodically by the node holding the "url_rankings" lease.
+-- score is the view or like count for count metrics and the decayed score for trending.
+CREATE TABLE url_rankings
+(
+    metric TINYINT NOT NULL, -- 1 views, 2 likes, 3 trending
+    time_window TINYINT NOT NULL, -- 0 all time, 1 24 hours, 2 7 days, 3 30 days
+    url_id BIGINT NOT NULL,
+    score DOUBLE NOT NULL,
+
+    computed_at BIGINT NOT NULL,
+    PRIMARY KEY (metric, time_window, url_id)
+) ENGINE = InnoDB;
+
+CREATE INDEX url_rankings_score_idx ON url_rankings(metric, time_window, score DESC);
+
+-- Item-to-item recommendations rebuilt periodically by the node holding the "url_related" lease.
+-- Only pairs read by at least a minimum number of the same clients are stored.
+CREATE TABLE url_related
+(
+    url_id BIGINT NOT NULL,
+    related_url_id BIGINT NOT NULL,
+    clients BIGINT NOT NULL, -- distinct clients that read both URLs
+    score DOUBLE NOT NULL,
+
+    computed_at BIGINT NOT NULL,
+    PRIMARY KEY (url_id, related_url_id)
+) ENGINE = InnoDB;
+
+CREATE INDEX url_related_related_url_id_idx ON url_related(related_url_id);
+
+-- WebSocket connections per URL and node, refreshed by each node while it has connections
+CREATE TABLE live_presence
+(
+    url_id BIGINT NOT NULL,
+    node_id BIGINT NOT NULL,
+    connections INT NOT NULL,
+    expires_at BIGINT NOT NULL,
+    PRIMARY KEY (url_id, node_id)
+) ENGINE = InnoDB;
+
+CREATE INDEX live_presence_expires_at_idx ON live_presence(expires_at);
+
+-- API keys of the admin API; only the SHA-256 of a key is stored
+CREATE TABLE api_keys
+(
+    id BIGINT PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    key_hash BINARY(32) NOT NULL,
+    scopes VARCHAR(255) NOT NULL, -- space separated
+    created_at BIGINT NOT NULL,
+    last_used_at BIGINT NOT NULL, -- 0 if never used
+    revoked_at BIGINT NOT NULL -- 0 while the key is active
+) ENGINE = InnoDB;
+
+CREATE UNIQUE INDEX api_keys_key_hash_idx ON api_keys(key_hash);
+
+-- Offsets added to the displayed view and like counts of a URL, e.g. counts imported
+-- from a previous analytics system. Reconciliation leaves them alone.
+CREATE TABLE count_offsets
+(
+    url_id BIGINT PRIMARY KEY,
+    view_offset BIGINT NOT NULL,
+    like_offset BIGINT NOT NULL,
+
+    updated_at BIGINT NOT NULL
+) ENGINE = InnoDB;
+
+-- Admin actions; rows are never updated or deleted
+CREATE TABLE audit_events
+(
+    id BIGINT PRIMARY KEY,
+    actor_key_id BIGINT NOT NULL, -- API key that performed the action, 0 for maintenance commands
+    action VARCHAR(64) NOT NULL,
+    target VARCHAR(1024) NOT NULL,
+    before_value TEXT NOT NULL, -- JSON
+    after_value TEXT NOT NULL, -- JSON
+
+    created_at BIGINT NOT NULL
+) ENGINE = InnoDB;
+
+CREATE INDEX audit_events_created_at_idx ON audit_events(created_at);
+CREATE INDEX audit_events_actor_key_id_created_at_idx ON audit_events(actor_key_id, created_at);
+CREATE INDEX audit_events_action_created_at_idx ON audit_events(action, created_at);
+CREATE INDEX audit_events_target_created_at_idx ON audit_events(target(255), created_at);
+
+-- Blocked clients, fingerprint hashes, IP networks and user agent patterns. Their
+-- submissions are answered as usual but not recorded.
+CREATE TABLE blocklist
+(
+    id BIGINT PRIMARY KEY,
+    kind TINYINT NOT NULL, -- 1 client id, 2 fingerprint hash, 3 IP network, 4 user agent pattern
+    value VARCHAR(255) NOT NULL,
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_by BIGINT NOT NULL, -- API key that added the entry
+
+    created_at BIGINT NOT NULL,
+    expires_at BIGINT NOT NULL -- 0 = never
+) ENGINE = InnoDB;
+
+CREATE UNIQUE INDEX blocklist_kind_value_idx ON blocklist(kind, value);
+CREATE INDEX blocklist_expires_at_idx ON blocklist(expires_at);
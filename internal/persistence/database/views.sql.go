@@ -9,33 +9,71 @@ import (
 	"context"
 )
 
-const urlInsert = `-- name: UrlInsert :exec
-INSERT INTO urls (id, url, created_at)
-VALUES (?, ?, ?)
+const urlInsert = `-- name: UrlInsert :execrows
+INSERT IGNORE INTO urls (id, url, url_hash, created_at)
+VALUES (?, ?, ?, ?)
 `
 
 type UrlInsertParams struct {
 	ID        int64  `json:"id"`
 	Url       string `json:"url"`
+	UrlHash   []byte `json:"url_hash"`
 	CreatedAt int64  `json:"created_at"`
 }
 
-func (q *Queries) UrlInsert(ctx context.Context, arg UrlInsertParams) error {
-	_, err := q.db.ExecContext(ctx, urlInsert, arg.ID, arg.Url, arg.CreatedAt)
-	return err
+func (q *Queries) UrlInsert(ctx context.Context, arg UrlInsertParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, urlInsert,
+		arg.ID,
+		arg.Url,
+		arg.UrlHash,
+		arg.CreatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
-const urlLookupByUrl = `-- name: UrlLookupByUrl :one
-SELECT id, url, created_at FROM urls WHERE url = ?
+const urlLookupByHash = `-- name: UrlLookupByHash :one
+SELECT id, url, url_hash, created_at FROM urls WHERE url_hash = ?
 `
 
-func (q *Queries) UrlLookupByUrl(ctx context.Context, url string) (Url, error) {
-	row := q.db.QueryRowContext(ctx, urlLookupByUrl, url)
+func (q *Queries) UrlLookupByHash(ctx context.Context, urlHash []byte) (Url, error) {
+	row := q.db.QueryRowContext(ctx, urlLookupByHash, urlHash)
 	var i Url
-	err := row.Scan(&i.ID, &i.Url, &i.CreatedAt)
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.UrlHash,
+		&i.CreatedAt,
+	)
 	return i, err
 }
 
+const viewCountAdd = `-- name: ViewCountAdd :exec
+UPDATE view_counts SET count = count + ?, updated_at = ? WHERE url_id = ?
+`
+
+type ViewCountAddParams struct {
+	Delta     int64 `json:"delta"`
+	UpdatedAt int64 `json:"updated_at"`
+	UrlID     int64 `json:"url_id"`
+}
+
+func (q *Queries) ViewCountAdd(ctx context.Context, arg ViewCountAddParams) error {
+	_, err := q.db.ExecContext(ctx, viewCountAdd, arg.Delta, arg.UpdatedAt, arg.UrlID)
+	return err
+}
+
+const viewCountDelete = `-- name: ViewCountDelete :exec
+DELETE FROM view_counts WHERE url_id = ?
+`
+
+func (q *Queries) ViewCountDelete(ctx context.Context, urlID int64) error {
+	_, err := q.db.ExecContext(ctx, viewCountDelete, urlID)
+	return err
+}
+
 const viewCountInsert = `-- name: ViewCountInsert :exec
 INSERT INTO view_counts (id, url_id, count, updated_at)
 VALUES (?, ?, 1, ?)
@@ -68,6 +106,21 @@ func (q *Queries) ViewCountLookup(ctx context.Context, urlID int64) (ViewCount,
 	return i, err
 }
 
+const viewCountRepoint = `-- name: ViewCountRepoint :exec
+UPDATE view_counts SET url_id = ?, updated_at = ? WHERE url_id = ?
+`
+
+type ViewCountRepointParams struct {
+	IntoID    int64 `json:"into_id"`
+	UpdatedAt int64 `json:"updated_at"`
+	FromID    int64 `json:"from_id"`
+}
+
+func (q *Queries) ViewCountRepoint(ctx context.Context, arg ViewCountRepointParams) error {
+	_, err := q.db.ExecContext(ctx, viewCountRepoint, arg.IntoID, arg.UpdatedAt, arg.FromID)
+	return err
+}
+
 const viewCountUpdate = `-- name: ViewCountUpdate :exec
 UPDATE view_counts SET count = count + 1, updated_at = ? WHERE url_id = ?
 `
@@ -83,15 +136,22 @@ func (q *Queries) ViewCountUpdate(ctx context.Context, arg ViewCountUpdateParams
 }
 
 const viewInsert = `-- name: ViewInsert :exec
-INSERT INTO views (id, url_id, client_id, created_at)
-VALUES (?, ?, ?, ?)
+INSERT INTO views (id, url_id, client_id, referrer, referrer_class, utm_source, utm_medium, utm_campaign, utm_term, utm_content, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 type ViewInsertParams struct {
-	ID        int64 `json:"id"`
-	UrlID     int64 `json:"url_id"`
-	ClientID  int64 `json:"client_id"`
-	CreatedAt int64 `json:"created_at"`
+	ID            int64  `json:"id"`
+	UrlID         int64  `json:"url_id"`
+	ClientID      int64  `json:"client_id"`
+	Referrer      string `json:"referrer"`
+	ReferrerClass string `json:"referrer_class"`
+	UtmSource     string `json:"utm_source"`
+	UtmMedium     string `json:"utm_medium"`
+	UtmCampaign   string `json:"utm_campaign"`
+	UtmTerm       string `json:"utm_term"`
+	UtmContent    string `json:"utm_content"`
+	CreatedAt     int64  `json:"created_at"`
 }
 
 func (q *Queries) ViewInsert(ctx context.Context, arg ViewInsertParams) error {
@@ -99,6 +159,13 @@ func (q *Queries) ViewInsert(ctx context.Context, arg ViewInsertParams) error {
 		arg.ID,
 		arg.UrlID,
 		arg.ClientID,
+		arg.Referrer,
+		arg.ReferrerClass,
+		arg.UtmSource,
+		arg.UtmMedium,
+		arg.UtmCampaign,
+		arg.UtmTerm,
+		arg.UtmContent,
 		arg.CreatedAt,
 	)
 	return err
@@ -0,0 +1,47 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// ClientDeviceUpsert stores the latest parsed device dimensions of a client.
+// The id is only used when the client has no device row yet.
+func (g *PersistenceClient) ClientDeviceUpsert(ctx context.Context, id int64, clientID int64, device types.DeviceInfo) error {
+	return g.db.ClientDeviceUpsert(ctx, database.ClientDeviceUpsertParams{
+		ID:             id,
+		ClientID:       clientID,
+		Browser:        device.Browser,
+		BrowserVersion: device.BrowserVersion,
+		Os:             device.OS,
+		DeviceClass:    device.DeviceClass,
+		Mobile:         device.Mobile,
+		UpdatedAt:      time.Now().UnixNano(),
+	})
+}
+
+// DeviceStatsByUrl returns view counts of a URL grouped by the device dimensions of the viewing clients.
+// Views from clients that never checked in are reported with unknown dimensions.
+func (g *PersistenceClient) DeviceStatsByUrl(ctx context.Context, urlID int64) ([]types.DeviceStatsEntry, error) {
+	rows, err := g.db.DeviceStatsByUrl(ctx, urlID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]types.DeviceStatsEntry, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, types.DeviceStatsEntry{
+			DeviceInfo: types.DeviceInfo{
+				Browser:        r.Browser,
+				BrowserVersion: r.BrowserVersion,
+				OS:             r.Os,
+				DeviceClass:    r.DeviceClass,
+				Mobile:         r.Mobile,
+			},
+			Count: r.Count,
+		})
+	}
+	return out, nil
+}
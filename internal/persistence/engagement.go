@@ -0,0 +1,84 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// EngagementRecord stores an engagement report of the view viewID by clientID.
+// Reports are cumulative, so only increases of reading time and scroll depth are applied.
+// It returns false if the view does not exist or belongs to another client.
+func (g *PersistenceClient) EngagementRecord(ctx context.Context, viewID int64, clientID int64, activeMs int64, maxScroll int32) (bool, error) {
+	view, err := g.db.EngagementViewLookup(ctx, viewID)
+	if err == sql.ErrNoRows || (err == nil && view.ClientID != clientID) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	err = g.db.EngagementUpsert(ctx, database.EngagementUpsertParams{
+		ViewID:    viewID,
+		UrlID:     view.UrlID,
+		ClientID:  clientID,
+		ActiveMs:  activeMs,
+		MaxScroll: maxScroll,
+		CreatedAt: view.CreatedAt,
+		UpdatedAt: time.Now().UnixNano(),
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// EngagementStatsByUrl returns the median reading time and the scroll depth distribution
+// of the views of urlID created in [from, to).
+func (g *PersistenceClient) EngagementStatsByUrl(ctx context.Context, urlID int64, from int64, to int64) (types.EngagementStats, error) {
+	summary, err := g.db.EngagementSummary(ctx, database.EngagementSummaryParams{
+		UrlID:  urlID,
+		FromTs: from,
+		ToTs:   to,
+	})
+	if err != nil {
+		return types.EngagementStats{}, err
+	}
+
+	stats := types.EngagementStats{
+		Views: summary.Views,
+		ScrollDepth: []types.ScrollDepthBucket{
+			{Range: "0-24", Count: summary.Scroll0},
+			{Range: "25-49", Count: summary.Scroll25},
+			{Range: "50-74", Count: summary.Scroll50},
+			{Range: "75-99", Count: summary.Scroll75},
+			{Range: "100", Count: summary.Scroll100},
+		},
+	}
+	if summary.Views == 0 {
+		return stats, nil
+	}
+
+	// Fetch the middle value, or both middle values for an even number of views
+	middle, err := g.db.EngagementActiveTimeAt(ctx, database.EngagementActiveTimeAtParams{
+		UrlID:  urlID,
+		FromTs: from,
+		ToTs:   to,
+		Offset: int32((summary.Views - 1) / 2),
+	})
+	if err != nil {
+		return types.EngagementStats{}, err
+	}
+	switch {
+	case len(middle) == 0:
+	case summary.Views%2 == 0 && len(middle) == 2:
+		stats.MedianActiveMs = (middle[0] + middle[1]) / 2
+	default:
+		stats.MedianActiveMs = middle[0]
+	}
+
+	return stats, nil
+}
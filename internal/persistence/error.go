@@ -0,0 +1,128 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// ErrorReportInsert adds a client error report to the group of its fingerprint and counts it
+// for the page it happened on. groupID and urlID are used in case the error group or the URL
+// record has to be created.
+func (g *PersistenceClient) ErrorReportInsert(ctx context.Context, groupID int64, urlID int64, report types.ErrorReport) error {
+	tx, err := g.pool.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelSerializable,
+	})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	txQueries := database.New(tx)
+	now := time.Now().UnixNano()
+
+	urlID, _, err = urlGetOrCreate(ctx, txQueries, urlID, report.URL, now)
+	if err != nil {
+		return err
+	}
+
+	err = txQueries.ErrorGroupUpsert(ctx, database.ErrorGroupUpsertParams{
+		ID:          groupID,
+		Fingerprint: report.Fingerprint,
+		Message:     report.Message,
+		Stack:       report.Stack,
+		Source:      report.Source,
+		Line:        report.Line,
+		Col:         report.Column,
+		UserAgent:   report.UserAgent,
+		FirstSeen:   now,
+		LastSeen:    now,
+	})
+	if err != nil {
+		return err
+	}
+
+	// The group may have existed with another ID
+	groupID, err = txQueries.ErrorGroupIDByFingerprint(ctx, report.Fingerprint)
+	if err != nil {
+		return err
+	}
+
+	err = txQueries.ErrorGroupUrlUpsert(ctx, database.ErrorGroupUrlUpsertParams{
+		GroupID:  groupID,
+		UrlID:    urlID,
+		LastSeen: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ErrorGroupList returns up to limit error groups last seen in [from, to), most frequent first,
+// each with up to maxURLs of the pages it was reported on most often. If site is not empty,
+// only groups and pages of the site are returned.
+func (g *PersistenceClient) ErrorGroupList(ctx context.Context, site string, from int64, to int64, limit int32, maxURLs int) ([]types.ErrorGroup, error) {
+	pattern := siteURLPattern(site)
+	rows, err := g.db.ErrorGroupList(ctx, database.ErrorGroupListParams{
+		FromTs:     from,
+		ToTs:       to,
+		UrlPattern: pattern,
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]types.ErrorGroup, 0, len(rows))
+	index := make(map[int64]int, len(rows))
+	ids := make([]int64, 0, len(rows))
+	for _, r := range rows {
+		index[r.ID] = len(groups)
+		ids = append(ids, r.ID)
+		groups = append(groups, types.ErrorGroup{
+			ID:          r.ID,
+			Fingerprint: hex.EncodeToString(r.Fingerprint),
+			Message:     r.Message,
+			Stack:       r.Stack,
+			Source:      r.Source,
+			Line:        r.Line,
+			Column:      r.Col,
+			UserAgent:   r.UserAgent,
+			Count:       r.Count,
+			FirstSeen:   r.FirstSeen,
+			LastSeen:    r.LastSeen,
+			URLs:        make([]types.ErrorGroupURL, 0),
+		})
+	}
+	if len(ids) == 0 {
+		return groups, nil
+	}
+
+	urls, err := g.db.ErrorGroupUrlsByGroups(ctx, database.ErrorGroupUrlsByGroupsParams{
+		GroupIds:   ids,
+		UrlPattern: pattern,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// rows are ordered by count within a group
+	for _, u := range urls {
+		group := &groups[index[u.GroupID]]
+		if len(group.URLs) >= maxURLs {
+			continue
+		}
+		group.URLs = append(group.URLs, types.ErrorGroupURL{
+			URL:      u.Url,
+			Count:    u.Count,
+			LastSeen: u.LastSeen,
+		})
+	}
+
+	return groups, nil
+}
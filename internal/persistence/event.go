@@ -0,0 +1,171 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// EventBatchInsert writes a batch of validated client events in one transaction and
+// reports for each event whether it was recorded and its canonical URL; duplicate likes
+// are not recorded, nor custom events whose new name exceeds maxNames per site or
+// maxClientNames per client. Names
+// are registered in the same transaction, so a failed batch does not use up the caps.
+// Any database error rolls back the whole batch.
+func (g *PersistenceClient) EventBatchInsert(ctx context.Context, events []types.ClientEvent, maxNames int64, maxClientNames int64) ([]types.EventInsertResult, error) {
+	tx, err := g.pool.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelSerializable,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	txQueries := database.New(tx)
+	now := time.Now().UnixNano()
+
+	results := make([]types.EventInsertResult, len(events))
+	for i, e := range events {
+		switch e.Type {
+		case types.EventTypeView:
+			results[i].URL, err = viewInsertWithCount(ctx, txQueries, types.ViewEvent{
+				ID:       e.ID,
+				URL:      e.URL,
+				ClientID: e.ClientID,
+				Referrer: e.Referrer,
+				Campaign: e.Campaign,
+			}, e.UrlID, e.CountID, e.CreatedAt)
+		case types.EventTypeLike:
+			var inserted bool
+			results[i].URL, inserted, err = likeInsertWithCount(ctx, txQueries, e.ID, e.URL, e.ClientID, e.UrlID, e.CountID, e.CreatedAt)
+			if err == nil && !inserted {
+				results[i].Status = types.EventDuplicate
+			}
+		case types.EventTypeCustom:
+			var registered bool
+			registered, err = eventNameRegister(ctx, txQueries, e.Site, e.Name, e.ClientID, maxNames, maxClientNames, now)
+			if err != nil {
+				break
+			}
+			if !registered {
+				results[i].Status = types.EventNameRejected
+				break
+			}
+			var urlID int64
+			urlID, results[i].URL, err = urlGetOrCreate(ctx, txQueries, e.UrlID, e.URL, now)
+			if err == nil {
+				err = txQueries.EventInsert(ctx, database.EventInsertParams{
+					ID:         e.ID,
+					UrlID:      urlID,
+					ClientID:   e.ClientID,
+					Name:       e.Name,
+					Properties: e.Properties,
+					CreatedAt:  e.CreatedAt,
+					ReceivedAt: now,
+				})
+			}
+		default:
+			err = fmt.Errorf("persistence: unknown event type %q", e.Type)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// eventNameRegister records name as a custom event name of site introduced by clientID.
+// It returns false without recording it if the site already has maxNames other names or
+// the client already introduced maxClientNames names.
+func eventNameRegister(ctx context.Context, q *database.Queries, site string, name string, clientID int64, maxNames int64, maxClientNames int64, now int64) (bool, error) {
+	exists, err := q.EventNameExists(ctx, database.EventNameExistsParams{
+		Site: site,
+		Name: name,
+	})
+	if err != nil {
+		return false, err
+	}
+	if exists > 0 {
+		return true, nil
+	}
+
+	count, err := q.EventNameCountBySite(ctx, site)
+	if err != nil {
+		return false, err
+	}
+	if count >= maxNames {
+		return false, nil
+	}
+	count, err = q.EventNameCountByClient(ctx, clientID)
+	if err != nil {
+		return false, err
+	}
+	if count >= maxClientNames {
+		return false, nil
+	}
+
+	err = q.EventNameInsert(ctx, database.EventNameInsertParams{
+		Site:      site,
+		Name:      name,
+		ClientID:  clientID,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// EventStatsByUrl returns the number of custom events of urlID in [from, to) per event name.
+func (g *PersistenceClient) EventStatsByUrl(ctx context.Context, urlID int64, from int64, to int64, limit int32) ([]types.EventStatsEntry, error) {
+	rows, err := g.db.EventStatsByUrl(ctx, database.EventStatsByUrlParams{
+		UrlID:  urlID,
+		FromTs: from,
+		ToTs:   to,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]types.EventStatsEntry, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, types.EventStatsEntry{
+			Name:    r.Name,
+			Count:   r.Count,
+			Clients: r.Clients,
+		})
+	}
+	return out, nil
+}
+
+// EventStatsBySite returns the number of custom events of all URLs of site in [from, to) per event name.
+func (g *PersistenceClient) EventStatsBySite(ctx context.Context, site string, from int64, to int64, limit int32) ([]types.EventStatsEntry, error) {
+	rows, err := g.db.EventStatsBySite(ctx, database.EventStatsBySiteParams{
+		FromTs:     from,
+		ToTs:       to,
+		UrlPattern: siteURLPattern(site),
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]types.EventStatsEntry, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, types.EventStatsEntry{
+			Name:    r.Name,
+			Count:   r.Count,
+			Clients: r.Clients,
+			URLs:    r.Urls,
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,38 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+)
+
+// LeaderLeaseAcquire tries to acquire or renew the named lease for holder until now+ttl.
+// It reports whether holder is the leader; an unexpired lease of another holder is left untouched.
+func (g *PersistenceClient) LeaderLeaseAcquire(ctx context.Context, name string, holder int64, ttl time.Duration) (bool, error) {
+	now := time.Now().UnixNano()
+
+	err := g.db.LeaderLeaseAcquire(ctx, database.LeaderLeaseAcquireParams{
+		Name:      name,
+		Holder:    holder,
+		ExpiresAt: now + int64(ttl),
+		Now:       now,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	lease, err := g.db.LeaderLeaseLookup(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	return lease.Holder == holder, nil
+}
+
+// LeaderLeaseRelease gives up the named lease if it is held by holder.
+func (g *PersistenceClient) LeaderLeaseRelease(ctx context.Context, name string, holder int64) error {
+	return g.db.LeaderLeaseRelease(ctx, database.LeaderLeaseReleaseParams{
+		Name:   name,
+		Holder: holder,
+	})
+}
@@ -0,0 +1,55 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// LiveSnapshot returns the view and like counts of urlID, the number of distinct clients
+// that viewed it or reported engagement since the given time and its live connections.
+func (g *PersistenceClient) LiveSnapshot(ctx context.Context, urlID int64, since int64) (types.LiveUpdate, error) {
+	row, err := g.db.LiveSnapshot(ctx, database.LiveSnapshotParams{
+		UrlID: urlID,
+		Since: since,
+		Now:   time.Now().UnixNano(),
+	})
+	if err != nil {
+		return types.LiveUpdate{}, err
+	}
+	return types.LiveUpdate{
+		Present: row.Present,
+		Readers: row.Readers,
+		Views:   row.Views,
+		Likes:   row.Likes,
+	}, nil
+}
+
+// PresenceUpdate stores the number of live connections of urlID on a node until expiresAt.
+// A count of 0 removes the entry.
+func (g *PersistenceClient) PresenceUpdate(ctx context.Context, urlID int64, nodeID int64, connections int32, expiresAt int64) error {
+	if connections <= 0 {
+		return g.db.PresenceDelete(ctx, database.PresenceDeleteParams{
+			UrlID:  urlID,
+			NodeID: nodeID,
+		})
+	}
+	return g.db.PresenceUpsert(ctx, database.PresenceUpsertParams{
+		UrlID:       urlID,
+		NodeID:      nodeID,
+		Connections: connections,
+		ExpiresAt:   expiresAt,
+	})
+}
+
+// PresenceDeleteNode removes all live connection counts of a node
+func (g *PersistenceClient) PresenceDeleteNode(ctx context.Context, nodeID int64) error {
+	return g.db.PresenceDeleteNode(ctx, nodeID)
+}
+
+// PresenceDeleteExpired removes live connection counts that expired before now
+func (g *PersistenceClient) PresenceDeleteExpired(ctx context.Context, now int64) error {
+	return g.db.PresenceDeleteExpired(ctx, now)
+}
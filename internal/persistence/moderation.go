@@ -0,0 +1,238 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// ViewPurge deletes the views selected by filter together with their engagement
+// reports and recomputes the view counter of the URL from the remaining views and
+// daily rollups. Rolled up views cannot be purged, so it fails with
+// types.ErrPurgeRolledUp if the range overlaps a rolled up day, unless
+// filter.SkipRolledUp starts the range after the latest rolled up day.
+func (g *PersistenceClient) ViewPurge(ctx context.Context, filter types.PurgeFilter) (types.PurgeResult, error) {
+	tx, err := g.pool.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelSerializable,
+	})
+	if err != nil {
+		return types.PurgeResult{}, err
+	}
+	defer tx.Rollback()
+
+	txQueries := database.New(tx)
+
+	if filter.SkipRolledUp {
+		latest, err := txQueries.ViewRollupDayLatest(ctx)
+		if err != nil {
+			return types.PurgeResult{}, err
+		}
+		// days are rolled up oldest first, so no later day is rolled up
+		if latest >= 0 {
+			filter.From = max(filter.From, latest+int64(24*time.Hour))
+		}
+	}
+
+	rolledUp, err := txQueries.ViewRollupDaysOverlap(ctx, database.ViewRollupDaysOverlapParams{
+		FromTs: filter.From,
+		ToTs:   filter.To,
+	})
+	if err != nil {
+		return types.PurgeResult{}, err
+	}
+	if rolledUp > 0 {
+		return types.PurgeResult{}, types.ErrPurgeRolledUp
+	}
+
+	result := types.PurgeResult{From: filter.From, To: filter.To}
+	result.CountBefore, err = txQueries.ViewCountDisplay(ctx, filter.UrlID)
+	if err != nil {
+		return types.PurgeResult{}, err
+	}
+
+	if len(filter.ClientIDs) > 0 {
+		result.Deleted, err = txQueries.ViewPurgeClients(ctx, database.ViewPurgeClientsParams{
+			UrlID:     filter.UrlID,
+			FromTs:    filter.From,
+			ToTs:      filter.To,
+			ClientIds: filter.ClientIDs,
+		})
+		if err == nil {
+			err = txQueries.EngagementPurgeClients(ctx, database.EngagementPurgeClientsParams{
+				UrlID:     filter.UrlID,
+				FromTs:    filter.From,
+				ToTs:      filter.To,
+				ClientIds: filter.ClientIDs,
+			})
+		}
+	} else {
+		result.Deleted, err = txQueries.ViewPurge(ctx, database.ViewPurgeParams{
+			UrlID:  filter.UrlID,
+			FromTs: filter.From,
+			ToTs:   filter.To,
+		})
+		if err == nil {
+			err = txQueries.EngagementPurge(ctx, database.EngagementPurgeParams{
+				UrlID:  filter.UrlID,
+				FromTs: filter.From,
+				ToTs:   filter.To,
+			})
+		}
+	}
+	if err != nil {
+		return types.PurgeResult{}, err
+	}
+
+	err = viewCountRecompute(ctx, txQueries, filter.UrlID, time.Now().UnixNano())
+	if err != nil {
+		return types.PurgeResult{}, err
+	}
+
+	result.CountAfter, err = txQueries.ViewCountDisplay(ctx, filter.UrlID)
+	if err != nil {
+		return types.PurgeResult{}, err
+	}
+
+	return result, tx.Commit()
+}
+
+// LikePurge deletes the likes selected by filter and recomputes the like counter
+// of the URL from the remaining likes
+func (g *PersistenceClient) LikePurge(ctx context.Context, filter types.PurgeFilter) (types.PurgeResult, error) {
+	tx, err := g.pool.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelSerializable,
+	})
+	if err != nil {
+		return types.PurgeResult{}, err
+	}
+	defer tx.Rollback()
+
+	txQueries := database.New(tx)
+
+	result := types.PurgeResult{From: filter.From, To: filter.To}
+	result.CountBefore, err = txQueries.LikeCountDisplay(ctx, filter.UrlID)
+	if err != nil {
+		return types.PurgeResult{}, err
+	}
+
+	if len(filter.ClientIDs) > 0 {
+		result.Deleted, err = txQueries.LikePurgeClients(ctx, database.LikePurgeClientsParams{
+			UrlID:     filter.UrlID,
+			FromTs:    filter.From,
+			ToTs:      filter.To,
+			ClientIds: filter.ClientIDs,
+		})
+	} else {
+		result.Deleted, err = txQueries.LikePurge(ctx, database.LikePurgeParams{
+			UrlID:  filter.UrlID,
+			FromTs: filter.From,
+			ToTs:   filter.To,
+		})
+	}
+	if err != nil {
+		return types.PurgeResult{}, err
+	}
+
+	err = likeCountRecompute(ctx, txQueries, filter.UrlID, time.Now().UnixNano())
+	if err != nil {
+		return types.PurgeResult{}, err
+	}
+
+	result.CountAfter, err = txQueries.LikeCountDisplay(ctx, filter.UrlID)
+	if err != nil {
+		return types.PurgeResult{}, err
+	}
+
+	return result, tx.Commit()
+}
+
+// viewCountRecompute sets the view counter of urlID to its retained views, not counting raw
+// views of rolled up days, plus its daily rollups. The total is written to the oldest counter
+// row and other rows of the URL, e.g. moved over by a merge, are deleted.
+func viewCountRecompute(ctx context.Context, q *database.Queries, urlID int64, now int64) error {
+	id, err := q.ViewCountFirstID(ctx, urlID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = q.ViewCountDeleteOthers(ctx, database.ViewCountDeleteOthersParams{
+		UrlID: urlID,
+		ID:    id,
+	})
+	if err != nil {
+		return err
+	}
+
+	return q.ViewCountRecompute(ctx, database.ViewCountRecomputeParams{
+		UrlID:     urlID,
+		UpdatedAt: now,
+		ID:        id,
+	})
+}
+
+// likeCountRecompute sets the like counter of urlID to its likes like viewCountRecompute
+func likeCountRecompute(ctx context.Context, q *database.Queries, urlID int64, now int64) error {
+	id, err := q.LikeCountFirstID(ctx, urlID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = q.LikeCountDeleteOthers(ctx, database.LikeCountDeleteOthersParams{
+		UrlID: urlID,
+		ID:    id,
+	})
+	if err != nil {
+		return err
+	}
+
+	return q.LikeCountRecompute(ctx, database.LikeCountRecomputeParams{
+		UrlID:     urlID,
+		UpdatedAt: now,
+		ID:        id,
+	})
+}
+
+// ViewCountDisplay returns the view count of urlID including its offset, 0 if it has none
+func (g *PersistenceClient) ViewCountDisplay(ctx context.Context, urlID int64) (int64, error) {
+	return g.db.ViewCountDisplay(ctx, urlID)
+}
+
+// LikeCountDisplay returns the like count of urlID including its offset, 0 if it has none
+func (g *PersistenceClient) LikeCountDisplay(ctx context.Context, urlID int64) (int64, error) {
+	return g.db.LikeCountDisplay(ctx, urlID)
+}
+
+// CountOffsetLookup returns the count offset of urlID, zero if it has none
+func (g *PersistenceClient) CountOffsetLookup(ctx context.Context, urlID int64) (types.CountOffset, error) {
+	row, err := g.db.CountOffsetLookup(ctx, urlID)
+	if err == sql.ErrNoRows {
+		return types.CountOffset{}, nil
+	}
+	if err != nil {
+		return types.CountOffset{}, err
+	}
+	return types.CountOffset{ViewOffset: row.ViewOffset, LikeOffset: row.LikeOffset}, nil
+}
+
+// CountOffsetSet replaces the count offset of urlID. A zero offset removes it.
+func (g *PersistenceClient) CountOffsetSet(ctx context.Context, urlID int64, offset types.CountOffset) error {
+	if offset == (types.CountOffset{}) {
+		return g.db.CountOffsetDelete(ctx, urlID)
+	}
+	return g.db.CountOffsetUpsert(ctx, database.CountOffsetUpsertParams{
+		UrlID:      urlID,
+		ViewOffset: offset.ViewOffset,
+		LikeOffset: offset.LikeOffset,
+		UpdatedAt:  time.Now().UnixNano(),
+	})
+}
@@ -0,0 +1,64 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// ViewInsertAnonymous records a view without a registered client, such as a tracking pixel request,
+// unless the URL was already viewed from the same ipHash on day. It returns the canonical URL of the
+// view, which differs from view.URL if that is an alias, and reports whether the view was recorded.
+// urlID and countID are used in case the URL record or the count row has to be created.
+func (g *PersistenceClient) ViewInsertAnonymous(ctx context.Context, view types.ViewEvent, ipHash []byte, day int64, urlID int64, countID int64) (string, bool, error) {
+	tx, err := g.pool.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelSerializable,
+	})
+	if err != nil {
+		return "", false, err
+	}
+	defer tx.Rollback()
+
+	txQueries := database.New(tx)
+	now := time.Now().UnixNano()
+
+	urlID, url, err := urlGetOrCreate(ctx, txQueries, urlID, view.URL, now)
+	if err != nil {
+		return "", false, err
+	}
+
+	inserted, err := txQueries.ViewIPDedupInsert(ctx, database.ViewIPDedupInsertParams{
+		UrlID:  urlID,
+		Day:    day,
+		IpHash: ipHash,
+	})
+	if err != nil {
+		return "", false, err
+	}
+	if inserted == 0 {
+		return url, false, nil
+	}
+
+	_, err = viewInsertWithCount(ctx, txQueries, view, urlID, countID, now)
+	if err != nil {
+		return "", false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", false, err
+	}
+	return url, true, nil
+}
+
+// ViewIPDedupDeleteBefore deletes up to limit pixel dedup entries of days before day
+// and returns the number of deleted entries.
+func (g *PersistenceClient) ViewIPDedupDeleteBefore(ctx context.Context, day int64, limit int32) (int64, error) {
+	return g.db.ViewIPDedupDeleteBefore(ctx, database.ViewIPDedupDeleteBeforeParams{
+		Day:   day,
+		Limit: limit,
+	})
+}
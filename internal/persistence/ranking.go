@@ -0,0 +1,151 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// RankingCounts returns the number of views or likes per URL created since from,
+// or the stored counters if from is 0. Views of days rolled up by the retention worker
+// are read from their rollups if the whole day is in range.
+func (g *PersistenceClient) RankingCounts(ctx context.Context, metric int32, from int64) ([]types.RankingScore, error) {
+	var rows []database.RankingViewsAllTimeRow
+	var err error
+	switch {
+	case metric == types.RankingViews && from == 0:
+		rows, err = g.db.RankingViewsAllTime(ctx)
+	case metric == types.RankingLikes && from == 0:
+		var likes []database.RankingLikesAllTimeRow
+		likes, err = g.db.RankingLikesAllTime(ctx)
+		for _, r := range likes {
+			rows = append(rows, database.RankingViewsAllTimeRow(r))
+		}
+	case metric == types.RankingViews:
+		var views []database.RankingViewsSinceRow
+		views, err = g.db.RankingViewsSince(ctx, from)
+		for _, r := range views {
+			rows = append(rows, database.RankingViewsAllTimeRow(r))
+		}
+	case metric == types.RankingLikes:
+		var likes []database.RankingLikesSinceRow
+		likes, err = g.db.RankingLikesSince(ctx, from)
+		for _, r := range likes {
+			rows = append(rows, database.RankingViewsAllTimeRow(r))
+		}
+	default:
+		return nil, fmt.Errorf("persistence: unknown ranking metric %d", metric)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]types.RankingScore, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, types.RankingScore{UrlID: r.UrlID, URL: r.Url, Score: float64(r.Count)})
+	}
+	return out, nil
+}
+
+// RankingDecayed returns the sum of exp((created_at-now)/tau) over the views or likes
+// of each URL created since from. Views of rolled up days count as if they all happened
+// at noon of their day.
+func (g *PersistenceClient) RankingDecayed(ctx context.Context, metric int32, now int64, tau int64, from int64) ([]types.RankingScore, error) {
+	var rows []database.RankingViewsDecayedRow
+	var err error
+	switch metric {
+	case types.RankingViews:
+		rows, err = g.db.RankingViewsDecayed(ctx, database.RankingViewsDecayedParams{
+			Now:    now,
+			Tau:    tau,
+			FromTs: from,
+		})
+	case types.RankingLikes:
+		var likes []database.RankingLikesDecayedRow
+		likes, err = g.db.RankingLikesDecayed(ctx, database.RankingLikesDecayedParams{
+			Now:    now,
+			Tau:    tau,
+			FromTs: from,
+		})
+		for _, r := range likes {
+			rows = append(rows, database.RankingViewsDecayedRow(r))
+		}
+	default:
+		return nil, fmt.Errorf("persistence: unknown ranking metric %d", metric)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]types.RankingScore, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, types.RankingScore{UrlID: r.UrlID, URL: r.Url, Score: r.Score})
+	}
+	return out, nil
+}
+
+// RankingReplace replaces a leaderboard with scores in one transaction, so readers
+// see either the previous or the new leaderboard.
+func (g *PersistenceClient) RankingReplace(ctx context.Context, metric int32, window int32, scores []types.RankingScore, computedAt int64) error {
+	tx, err := g.pool.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelSerializable,
+	})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	txQueries := database.New(tx)
+
+	err = txQueries.RankingDelete(ctx, database.RankingDeleteParams{
+		Metric:     metric,
+		TimeWindow: window,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, s := range scores {
+		err = txQueries.RankingInsert(ctx, database.RankingInsertParams{
+			Metric:     metric,
+			TimeWindow: window,
+			UrlID:      s.UrlID,
+			Score:      s.Score,
+			ComputedAt: computedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RankingTop returns the highest ranked URLs of a leaderboard, limited to a site if it is not empty,
+// and the time the leaderboard was computed, 0 if it is empty.
+func (g *PersistenceClient) RankingTop(ctx context.Context, metric int32, window int32, site string, limit int32) ([]types.RankingEntry, int64, error) {
+	rows, err := g.db.RankingTop(ctx, database.RankingTopParams{
+		Metric:     metric,
+		TimeWindow: window,
+		UrlPattern: siteURLPattern(site),
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var computedAt int64
+	out := make([]types.RankingEntry, 0, len(rows))
+	for i, r := range rows {
+		computedAt = max(computedAt, r.ComputedAt)
+		out = append(out, types.RankingEntry{
+			Rank:  i + 1,
+			URL:   r.Url,
+			Score: r.Score,
+		})
+	}
+	return out, computedAt, nil
+}
@@ -0,0 +1,143 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// CountReconcileBatch compares the stored counters of up to limit URLs with IDs
+// greater than afterID against their raw views and likes, ordered by URL ID.
+// It uses plain reads so it does not block view and like inserts.
+func (g *PersistenceClient) CountReconcileBatch(ctx context.Context, afterID int64, limit int32) ([]types.CountCheck, error) {
+	rows, err := g.db.CountReconcileBatch(ctx, database.CountReconcileBatchParams{
+		AfterID: afterID,
+		Limit:   limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]types.CountCheck, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, types.CountCheck{
+			UrlID:     r.UrlID,
+			URL:       r.Url,
+			ViewCount: r.ViewCount,
+			ViewRows:  r.ViewRows,
+			LikeCount: r.LikeCount,
+			LikeRows:  r.LikeRows,
+		})
+	}
+	return out, nil
+}
+
+// ViewCountReconcile sets the view count of urlID to actual if it still equals expected,
+// creating the count row with id if there is none. It returns false without changing
+// anything when the count was modified concurrently; the next run will pick it up again.
+func (g *PersistenceClient) ViewCountReconcile(ctx context.Context, id int64, urlID int64, expected int64, actual int64) (bool, error) {
+	now := time.Now().UnixNano()
+
+	n, err := g.db.ViewCountReconcile(ctx, database.ViewCountReconcileParams{
+		Count:     actual,
+		UpdatedAt: now,
+		UrlID:     urlID,
+		Expected:  expected,
+	})
+	if err != nil {
+		return false, err
+	}
+	if n > 0 {
+		return true, nil
+	}
+	if expected != 0 {
+		return false, nil
+	}
+
+	// No count row yet; create it unless a concurrent view did
+	tx, err := g.pool.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelSerializable,
+	})
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	txQueries := database.New(tx)
+
+	_, err = txQueries.ViewCountLookup(ctx, urlID)
+	if err == nil {
+		return false, nil
+	}
+	if err != sql.ErrNoRows {
+		return false, err
+	}
+
+	err = txQueries.ViewCountCreate(ctx, database.ViewCountCreateParams{
+		ID:        id,
+		UrlID:     urlID,
+		Count:     actual,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// LikeCountReconcile sets the like count of urlID to actual if it still equals expected,
+// creating the count row with id if there is none. It returns false without changing
+// anything when the count was modified concurrently; the next run will pick it up again.
+func (g *PersistenceClient) LikeCountReconcile(ctx context.Context, id int64, urlID int64, expected int64, actual int64) (bool, error) {
+	now := time.Now().UnixNano()
+
+	n, err := g.db.LikeCountReconcile(ctx, database.LikeCountReconcileParams{
+		Count:     actual,
+		UpdatedAt: now,
+		UrlID:     urlID,
+		Expected:  expected,
+	})
+	if err != nil {
+		return false, err
+	}
+	if n > 0 {
+		return true, nil
+	}
+	if expected != 0 {
+		return false, nil
+	}
+
+	// No count row yet; create it unless a concurrent like did
+	tx, err := g.pool.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelSerializable,
+	})
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	txQueries := database.New(tx)
+
+	_, err = txQueries.LikeCountLookup(ctx, urlID)
+	if err == nil {
+		return false, nil
+	}
+	if err != sql.ErrNoRows {
+		return false, err
+	}
+
+	err = txQueries.LikeCountCreate(ctx, database.LikeCountCreateParams{
+		ID:        id,
+		UrlID:     urlID,
+		Count:     actual,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
@@ -0,0 +1,32 @@
+package persistence
+
+import (
+	"context"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// ReferrerStatsByUrl returns the top traffic sources of a URL for views created in [from, to).
+func (g *PersistenceClient) ReferrerStatsByUrl(ctx context.Context, urlID int64, from int64, to int64, limit int32) ([]types.ReferrerStatsEntry, error) {
+	rows, err := g.db.ReferrerStatsByUrl(ctx, database.ReferrerStatsByUrlParams{
+		UrlID:  urlID,
+		FromTs: from,
+		ToTs:   to,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]types.ReferrerStatsEntry, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, types.ReferrerStatsEntry{
+			Referrer: types.Referrer{
+				Source: r.Referrer,
+				Class:  r.ReferrerClass,
+			},
+			Count: r.Count,
+		})
+	}
+	return out, nil
+}
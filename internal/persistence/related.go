@@ -0,0 +1,91 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// RelatedVisits returns the distinct URLs viewed since from by up to clients clients with an ID
+// greater than afterClientID, grouped by client in ascending ID order and most recent first within
+// a client, and the ID of the last of those clients, 0 if there are none. Anonymous views are ignored.
+func (g *PersistenceClient) RelatedVisits(ctx context.Context, from int64, afterClientID int64, clients int32) ([]types.RelatedVisit, int64, error) {
+	ids, err := g.db.RelatedClientBatch(ctx, database.RelatedClientBatchParams{
+		AfterClientID: afterClientID,
+		FromTs:        from,
+		Limit:         clients,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(ids) == 0 {
+		return nil, 0, nil
+	}
+	lastClientID := ids[len(ids)-1]
+
+	rows, err := g.db.RelatedVisitsByClients(ctx, database.RelatedVisitsByClientsParams{
+		AfterClientID: afterClientID,
+		LastClientID:  lastClientID,
+		FromTs:        from,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	out := make([]types.RelatedVisit, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, types.RelatedVisit{ClientID: r.ClientID, UrlID: r.UrlID})
+	}
+	return out, lastClientID, nil
+}
+
+// RelatedReplace replaces all stored recommendations with pairs in one transaction.
+func (g *PersistenceClient) RelatedReplace(ctx context.Context, pairs []types.RelatedPair, computedAt int64) error {
+	tx, err := g.pool.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelSerializable,
+	})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	txQueries := database.New(tx)
+
+	if err = txQueries.RelatedDeleteAll(ctx); err != nil {
+		return err
+	}
+
+	for _, p := range pairs {
+		err = txQueries.RelatedInsert(ctx, database.RelatedInsertParams{
+			UrlID:        p.UrlID,
+			RelatedUrlID: p.RelatedUrlID,
+			Clients:      p.Clients,
+			Score:        p.Score,
+			ComputedAt:   computedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RelatedByUrl returns the URLs recommended for urlID with the highest score, limited to a site
+// if it is not empty.
+func (g *PersistenceClient) RelatedByUrl(ctx context.Context, urlID int64, site string, limit int32) ([]types.RelatedEntry, error) {
+	rows, err := g.db.RelatedByUrl(ctx, database.RelatedByUrlParams{
+		UrlID:      urlID,
+		UrlPattern: siteURLPattern(site),
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]types.RelatedEntry, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, types.RelatedEntry{URL: r.Url, Score: r.Score})
+	}
+	return out, nil
+}
@@ -0,0 +1,107 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// ViewOldestBefore returns the creation time of the oldest raw view created before
+// the given time, or 0 if there is none.
+func (g *PersistenceClient) ViewOldestBefore(ctx context.Context, before int64) (int64, error) {
+	return g.db.ViewOldestBefore(ctx, before)
+}
+
+// ViewDailyAggregate aggregates the raw views of the UTC day starting at day per URL.
+// Anonymous pixel views have no client; they are recorded once per IP address, URL and
+// day, so each of them counts as one unique client. The returned rollups have no ID yet.
+func (g *PersistenceClient) ViewDailyAggregate(ctx context.Context, day int64) ([]types.ViewRollup, error) {
+	rows, err := g.db.ViewDailyAggregate(ctx, database.ViewDailyAggregateParams{
+		FromTs: day,
+		ToTs:   day + int64(24*time.Hour),
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]types.ViewRollup, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, types.ViewRollup{
+			UrlID:         r.UrlID,
+			Day:           day,
+			Views:         r.Views,
+			UniqueClients: r.UniqueClients,
+		})
+	}
+	return out, nil
+}
+
+// ViewRollupDone reports whether the raw views of the UTC day starting at day have been rolled up.
+func (g *PersistenceClient) ViewRollupDone(ctx context.Context, day int64) (bool, error) {
+	_, err := g.db.ViewRollupDayLookup(ctx, day)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ViewRollupInsert stores the rollups of the UTC day starting at day and marks the day
+// as rolled up in one transaction. It returns false if the day was already rolled up.
+func (g *PersistenceClient) ViewRollupInsert(ctx context.Context, day int64, rollups []types.ViewRollup) (bool, error) {
+	tx, err := g.pool.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelSerializable,
+	})
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	txQueries := database.New(tx)
+	now := time.Now().UnixNano()
+
+	_, err = txQueries.ViewRollupDayLookup(ctx, day)
+	if err == nil {
+		return false, nil
+	}
+	if err != sql.ErrNoRows {
+		return false, err
+	}
+
+	var views int64
+	for _, r := range rollups {
+		err = txQueries.ViewRollupInsert(ctx, database.ViewRollupInsertParams{
+			ID:            r.ID,
+			UrlID:         r.UrlID,
+			Day:           day,
+			Views:         r.Views,
+			UniqueClients: r.UniqueClients,
+			CreatedAt:     now,
+		})
+		if err != nil {
+			return false, err
+		}
+		views += r.Views
+	}
+
+	err = txQueries.ViewRollupDayInsert(ctx, database.ViewRollupDayInsertParams{
+		Day:       day,
+		Views:     views,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// ViewDeleteBefore deletes up to limit of the oldest raw views created before the given time
+// and returns the number of deleted rows.
+func (g *PersistenceClient) ViewDeleteBefore(ctx context.Context, before int64, limit int32) (int64, error) {
+	return g.db.ViewDeleteBefore(ctx, database.ViewDeleteBeforeParams{
+		CreatedAt: before,
+		Limit:     limit,
+	})
+}
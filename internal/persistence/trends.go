@@ -0,0 +1,48 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+)
+
+// ViewDailyCounts returns the number of views of urlID on each of the given number of UTC days
+// starting at fromDay, the start of a UTC day. Days rolled up by the retention worker are read
+// from their rollups, other days are counted from the raw views.
+func (g *PersistenceClient) ViewDailyCounts(ctx context.Context, urlID int64, fromDay int64, days int) ([]int64, error) {
+	const day = int64(24 * time.Hour)
+	toDay := fromDay + int64(days)*day
+	counts := make([]int64, days)
+
+	raw, err := g.db.ViewDailyCountsByUrl(ctx, database.ViewDailyCountsByUrlParams{
+		UrlID:  urlID,
+		FromTs: fromDay,
+		ToTs:   toDay,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range raw {
+		if i := (r.DayIndex*day - fromDay) / day; i >= 0 && i < int64(days) {
+			counts[i] = r.Views
+		}
+	}
+
+	// Rollups cover whole days, so they replace raw views left over from an interrupted deletion
+	rollups, err := g.db.ViewRollupsByUrl(ctx, database.ViewRollupsByUrlParams{
+		UrlID:  urlID,
+		FromTs: fromDay,
+		ToTs:   toDay,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rollups {
+		if i := (r.Day - fromDay) / day; i >= 0 && i < int64(days) {
+			counts[i] = r.Views
+		}
+	}
+
+	return counts, nil
+}
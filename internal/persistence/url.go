@@ -0,0 +1,471 @@
+package persistence
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+var (
+	ErrUrlMergeSelf      = errors.New("persistence: cannot merge a url into itself")
+	ErrUrlAliasExists    = errors.New("persistence: url alias already exists")
+	ErrUrlAliasHasRecord = errors.New("persistence: alias has its own url record, merge it instead")
+)
+
+// urlHash returns the key of a normalized URL in the unique url_hash and alias_hash
+// indexes. It must match UNHEX(SHA2(url, 256)) used by the backfill queries.
+func urlHash(url string) []byte {
+	sum := sha256.Sum256([]byte(url))
+	return sum[:]
+}
+
+// urlGetOrCreate resolves url to its URL record ID and canonical URL, following aliases,
+// and creates the record with id if it does not exist. Concurrent callers inserting the same URL
+// are serialized by the unique url_hash index, so all of them get the same ID. A
+// duplicate is ignored rather than updated, so it affects no rows even when the DSN
+// sets clientFoundRows.
+func urlGetOrCreate(ctx context.Context, q *database.Queries, id int64, url string, now int64) (int64, string, error) {
+	hash := urlHash(url)
+
+	alias, err := q.UrlAliasLookup(ctx, hash)
+	if err == nil {
+		record, err := q.UrlLookupByID(ctx, alias.UrlID)
+		if err != nil {
+			return 0, "", err
+		}
+		return record.ID, record.Url, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, "", err
+	}
+
+	inserted, err := q.UrlInsert(ctx, database.UrlInsertParams{
+		ID:        id,
+		Url:       url,
+		UrlHash:   hash,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return 0, "", err
+	}
+	if inserted == 1 {
+		return id, url, nil
+	}
+
+	// Lost the race or the URL already existed
+	record, err := q.UrlLookupByHash(ctx, hash)
+	if err != nil {
+		return 0, "", err
+	}
+	return record.ID, url, nil
+}
+
+// UrlLookupByUrl looks up a normalized URL, resolving aliases to their canonical URL record.
+func (g *PersistenceClient) UrlLookupByUrl(ctx context.Context, url string) (types.Url, error) {
+	hash := urlHash(url)
+	alias, err := g.db.UrlAliasLookup(ctx, hash)
+	if err == nil {
+		return g.db.UrlLookupByID(ctx, alias.UrlID)
+	}
+	if err != sql.ErrNoRows {
+		return types.Url{}, err
+	}
+	return g.db.UrlLookupByHash(ctx, hash)
+}
+
+// UrlGetOrCreate returns the ID of the URL record of a normalized URL, creating it
+// with id if it does not exist yet. Aliases resolve to their canonical URL record.
+func (g *PersistenceClient) UrlGetOrCreate(ctx context.Context, id int64, url string) (int64, error) {
+	urlID, _, err := urlGetOrCreate(ctx, g.db, id, url, time.Now().UnixNano())
+	return urlID, err
+}
+
+// UrlAliasCreate makes alias resolve to the URL record urlID.
+// The alias must not have a URL record of its own; such URLs have to be merged instead.
+func (g *PersistenceClient) UrlAliasCreate(ctx context.Context, id int64, alias string, urlID int64) error {
+	tx, err := g.pool.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelSerializable,
+	})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	txQueries := database.New(tx)
+
+	if _, err := txQueries.UrlLookupByID(ctx, urlID); err != nil {
+		return err
+	}
+
+	hash := urlHash(alias)
+
+	_, err = txQueries.UrlAliasLookup(ctx, hash)
+	if err == nil {
+		return ErrUrlAliasExists
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	_, err = txQueries.UrlLookupByHash(ctx, hash)
+	if err == nil {
+		return ErrUrlAliasHasRecord
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	err = txQueries.UrlAliasInsert(ctx, database.UrlAliasInsertParams{
+		ID:        id,
+		Alias:     alias,
+		AliasHash: hash,
+		UrlID:     urlID,
+		CreatedAt: time.Now().UnixNano(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UrlAliasList returns all aliases resolving to the URL record urlID.
+func (g *PersistenceClient) UrlAliasList(ctx context.Context, urlID int64) ([]types.UrlAlias, error) {
+	return g.db.UrlAliasListByUrlID(ctx, urlID)
+}
+
+// UrlMerge moves all views and likes of the URL fromID to the URL intoID, sums their
+// counts and turns the old URL into an alias of the target. Likes of clients that
+// already liked the target URL are dropped to preserve the unique like constraint.
+// aliasID is used as the ID of the alias row created for the old URL.
+func (g *PersistenceClient) UrlMerge(ctx context.Context, fromID int64, intoID int64, aliasID int64) (types.UrlMergeResult, error) {
+	if fromID == intoID {
+		return types.UrlMergeResult{}, ErrUrlMergeSelf
+	}
+
+	tx, err := g.pool.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelSerializable,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+	defer tx.Rollback()
+
+	txQueries := database.New(tx)
+	now := time.Now().UnixNano()
+
+	from, err := txQueries.UrlLookupByID(ctx, fromID)
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+	into, err := txQueries.UrlLookupByID(ctx, intoID)
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	result := types.UrlMergeResult{
+		FromURL: from.Url,
+		IntoURL: into.Url,
+	}
+
+	// Drop likes that would violate the unique (url_id, client_id) index after re-pointing
+	result.DuplicateLikes, err = txQueries.UrlMergeDeleteDuplicateLikes(ctx, database.UrlMergeDeleteDuplicateLikesParams{
+		IntoID: intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	result.ViewsMoved, err = txQueries.UrlMergeViews(ctx, database.UrlMergeViewsParams{
+		IntoID: intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	// Fold daily rollups of days both URLs have into the target, then move the rest
+	err = txQueries.UrlMergeRollupsAdd(ctx, database.UrlMergeRollupsAddParams{
+		FromID: fromID,
+		IntoID: intoID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	err = txQueries.UrlMergeRollupsDeleteOverlap(ctx, database.UrlMergeRollupsDeleteOverlapParams{
+		IntoID: intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	err = txQueries.UrlMergeRollups(ctx, database.UrlMergeRollupsParams{
+		IntoID: intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	err = txQueries.UrlMergeEvents(ctx, database.UrlMergeEventsParams{
+		IntoID: intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	err = txQueries.UrlMergeEngagements(ctx, database.UrlMergeEngagementsParams{
+		IntoID: intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	// Fold vitals buckets both URLs have into the target, then move the rest
+	err = txQueries.UrlMergeVitalsAdd(ctx, database.UrlMergeVitalsAddParams{
+		FromID: fromID,
+		IntoID: intoID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	err = txQueries.UrlMergeVitalsDeleteOverlap(ctx, database.UrlMergeVitalsDeleteOverlapParams{
+		IntoID: intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	err = txQueries.UrlMergeVitals(ctx, database.UrlMergeVitalsParams{
+		IntoID: intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	// Fold error counts of groups reported on both URLs into the target, then move the rest
+	err = txQueries.UrlMergeErrorUrlsAdd(ctx, database.UrlMergeErrorUrlsAddParams{
+		FromID: fromID,
+		IntoID: intoID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	err = txQueries.UrlMergeErrorUrlsDeleteOverlap(ctx, database.UrlMergeErrorUrlsDeleteOverlapParams{
+		IntoID: intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	err = txQueries.UrlMergeErrorUrls(ctx, database.UrlMergeErrorUrlsParams{
+		IntoID: intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	// Pixel dedup entries only live for a day, so those of the merged URL are dropped
+	err = txQueries.UrlMergeIPDedupDelete(ctx, fromID)
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	// Sum count offsets of both URLs into the target, then move the offset of the source
+	err = txQueries.UrlMergeCountOffsetsAdd(ctx, database.UrlMergeCountOffsetsAddParams{
+		FromID: fromID,
+		IntoID: intoID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	err = txQueries.UrlMergeCountOffsetsDeleteOverlap(ctx, database.UrlMergeCountOffsetsDeleteOverlapParams{
+		IntoID: intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	err = txQueries.UrlMergeCountOffsets(ctx, database.UrlMergeCountOffsetsParams{
+		IntoID: intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	// Leaderboards and recommendations pick the merged views up on their next rebuild
+	err = txQueries.UrlMergeRankingsDelete(ctx, fromID)
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	err = txQueries.UrlMergeRelatedDelete(ctx, database.UrlMergeRelatedDeleteParams{
+		UrlID:        fromID,
+		RelatedUrlID: fromID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	result.LikesMoved, err = txQueries.UrlMergeLikes(ctx, database.UrlMergeLikesParams{
+		IntoID: intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	// Sum view counts. If the target has no count row yet, re-point the old one.
+	fromViews, err := txQueries.ViewCountLookup(ctx, fromID)
+	if err != nil && err != sql.ErrNoRows {
+		return types.UrlMergeResult{}, err
+	}
+	if err == nil {
+		result.ViewCount = fromViews.Count
+		_, err = txQueries.ViewCountLookup(ctx, intoID)
+		switch err {
+		case nil:
+			err = txQueries.ViewCountAdd(ctx, database.ViewCountAddParams{
+				Delta:     fromViews.Count,
+				UpdatedAt: now,
+				UrlID:     intoID,
+			})
+			if err == nil {
+				err = txQueries.ViewCountDelete(ctx, fromID)
+			}
+		case sql.ErrNoRows:
+			err = txQueries.ViewCountRepoint(ctx, database.ViewCountRepointParams{
+				IntoID:    intoID,
+				UpdatedAt: now,
+				FromID:    fromID,
+			})
+		}
+		if err != nil {
+			return types.UrlMergeResult{}, err
+		}
+	}
+
+	// Sum like counts, minus the dropped duplicate likes.
+	fromLikes, err := txQueries.LikeCountLookup(ctx, fromID)
+	if err != nil && err != sql.ErrNoRows {
+		return types.UrlMergeResult{}, err
+	}
+	if err == nil {
+		result.LikeCount = fromLikes.Count - result.DuplicateLikes
+		_, err = txQueries.LikeCountLookup(ctx, intoID)
+		switch err {
+		case nil:
+			err = txQueries.LikeCountAdd(ctx, database.LikeCountAddParams{
+				Delta:     result.LikeCount,
+				UpdatedAt: now,
+				UrlID:     intoID,
+			})
+			if err == nil {
+				err = txQueries.LikeCountDelete(ctx, fromID)
+			}
+		case sql.ErrNoRows:
+			err = txQueries.LikeCountRepoint(ctx, database.LikeCountRepointParams{
+				IntoID:    intoID,
+				UpdatedAt: now,
+				FromID:    fromID,
+			})
+			if err == nil && result.DuplicateLikes > 0 {
+				err = txQueries.LikeCountAdd(ctx, database.LikeCountAddParams{
+					Delta:     -result.DuplicateLikes,
+					UpdatedAt: now,
+					UrlID:     intoID,
+				})
+			}
+		}
+		if err != nil {
+			return types.UrlMergeResult{}, err
+		}
+	}
+
+	// Keep old links working: aliases of the old URL and the old URL itself now resolve to the target
+	err = txQueries.UrlAliasRepoint(ctx, database.UrlAliasRepointParams{
+		IntoID: intoID,
+		FromID: fromID,
+	})
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	// Duplicate records of the same URL need no alias; the target already matches it
+	if from.Url != into.Url {
+		err = txQueries.UrlAliasInsert(ctx, database.UrlAliasInsertParams{
+			ID:        aliasID,
+			Alias:     from.Url,
+			AliasHash: urlHash(from.Url),
+			UrlID:     intoID,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return types.UrlMergeResult{}, err
+		}
+	}
+
+	err = txQueries.UrlDelete(ctx, fromID)
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return types.UrlMergeResult{}, err
+	}
+
+	return result, nil
+}
+
+// UrlListDuplicates returns the URLs that have more than one URL record. Such records
+// predate the unique url_hash index and can be merged with UrlMerge.
+func (g *PersistenceClient) UrlListDuplicates(ctx context.Context) ([]string, error) {
+	rows, err := g.db.UrlListDuplicates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, r.Url)
+	}
+	return out, nil
+}
+
+// UrlListByUrl returns all URL records of a normalized URL, oldest first.
+func (g *PersistenceClient) UrlListByUrl(ctx context.Context, url string) ([]types.Url, error) {
+	return g.db.UrlListByUrl(ctx, url)
+}
+
+// UrlBackfillHashes fills in url_hash and alias_hash of rows created before the
+// hash columns existed and returns the number of updated rows.
+func (g *PersistenceClient) UrlBackfillHashes(ctx context.Context) (int64, error) {
+	urls, err := g.db.UrlBackfillHashes(ctx)
+	if err != nil {
+		return 0, err
+	}
+	aliases, err := g.db.UrlAliasBackfillHashes(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return urls + aliases, nil
+}
@@ -0,0 +1,82 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"telemetry.gosuda.org/telemetry/internal/persistence/database"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+// VitalInsert counts the web vitals measured on a page load of url by clientID in the histogram
+// of the current UTC day and the device class of the client. urlID is used in case the URL
+// record has to be created.
+func (g *PersistenceClient) VitalInsert(ctx context.Context, url string, clientID int64, urlID int64, measurements []types.VitalMeasurement) error {
+	const day = int64(24 * time.Hour)
+
+	tx, err := g.pool.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelSerializable,
+	})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	txQueries := database.New(tx)
+	now := time.Now().UnixNano()
+
+	urlID, _, err = urlGetOrCreate(ctx, txQueries, urlID, url, now)
+	if err != nil {
+		return err
+	}
+
+	deviceClass := types.DeviceClassUnknown
+	device, err := txQueries.ClientDeviceLookup(ctx, clientID)
+	if err == nil {
+		deviceClass = device.DeviceClass
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	for _, m := range measurements {
+		err = txQueries.VitalBucketAdd(ctx, database.VitalBucketAddParams{
+			UrlID:       urlID,
+			Day:         now - now%day,
+			Metric:      m.Metric,
+			DeviceClass: deviceClass,
+			Bucket:      m.Bucket,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// VitalBucketsByUrl returns the web vitals histograms of urlID per metric and device class,
+// summed over the UTC days starting in [from, to). from is rounded down to the start of its day.
+func (g *PersistenceClient) VitalBucketsByUrl(ctx context.Context, urlID int64, from int64, to int64) ([]types.VitalBucketCount, error) {
+	const day = int64(24 * time.Hour)
+
+	rows, err := g.db.VitalBucketsByUrl(ctx, database.VitalBucketsByUrlParams{
+		UrlID:   urlID,
+		FromDay: from - from%day,
+		ToTs:    to,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]types.VitalBucketCount, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, types.VitalBucketCount{
+			Metric:      r.Metric,
+			DeviceClass: r.DeviceClass,
+			Bucket:      r.Bucket,
+			Samples:     r.Samples,
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+const (
+	_BLOCKLIST_REFRESH_INTERVAL = 30 * time.Second
+	_BLOCKLIST_LEASE_TTL        = time.Minute * 5
+)
+
+// loadBlocklist replaces the cached blocklist with the active entries in the database.
+// Blocked fingerprints are resolved to the clients that checked in with them.
+func (g *Server) loadBlocklist(ctx context.Context) error {
+	entries, err := g.ps.BlocklistActive(ctx, time.Now().UnixNano())
+	if err != nil {
+		return err
+	}
+
+	var fingerprints []string
+	for _, e := range entries {
+		if e.Kind == types.BlockFingerprint {
+			fingerprints = append(fingerprints, e.Value)
+		}
+	}
+	clients, err := g.ps.BlocklistFingerprintClients(ctx, fingerprints)
+	if err != nil {
+		return err
+	}
+
+	g.blocklist.Store(core.NewBlocklist(entries, clients))
+	return nil
+}
+
+// blocklistWorker reloads the blocklist so entries added on other nodes, expired
+// entries and new clients with blocked fingerprints are picked up. The node holding
+// the "blocklist" lease deletes expired entries.
+func (g *Server) blocklistWorker() {
+	ticker := time.NewTicker(_BLOCKLIST_REFRESH_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx := context.Background()
+			if err := g.loadBlocklist(ctx); err != nil {
+				log.Error().Err(err).Msg("failed to load blocklist")
+			}
+
+			if !g.isLeader("blocklist", _BLOCKLIST_LEASE_TTL) {
+				continue
+			}
+			deleted, err := g.ps.BlocklistDeleteExpired(ctx, time.Now().UnixNano())
+			if err != nil {
+				log.Error().Err(err).Msg("failed to delete expired blocklist entries")
+				continue
+			}
+			if deleted > 0 {
+				log.Debug().Int64("deleted", deleted).Msg("expired blocklist entries deleted")
+			}
+		case <-g.stopCh:
+			return
+		}
+	}
+}
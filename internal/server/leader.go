@@ -0,0 +1,22 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// isLeader reports whether this node holds the named leader lease, acquiring or
+// renewing it for ttl. Jobs guarded by a lease run on a single node at a time.
+func (g *Server) isLeader(name string, ttl time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	ok, err := g.ps.LeaderLeaseAcquire(ctx, name, g.leaderID, ttl)
+	if err != nil {
+		log.Error().Err(err).Str("lease", name).Msg("failed to acquire leader lease")
+		return false
+	}
+	return ok
+}
@@ -0,0 +1,361 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+const (
+	_LIVE_POLL_INTERVAL   = 3 * time.Second
+	_LIVE_MAX_SUBSCRIBERS = 10000
+	_LIVE_NOTIFY_BUFFER   = 256
+
+	_PRESENCE_REFRESH  = 10 * time.Second // stored connection counts are refreshed this often
+	_PRESENCE_TTL      = 30 * time.Second // and count until this long after their last refresh
+	_PRESENCE_GC_TICKS = 20               // poll intervals between deletions of expired counts
+)
+
+var (
+	ErrLiveFull    = errors.New("server: too many live subscribers")
+	ErrLiveStopped = errors.New("server: live hub stopped")
+)
+
+// LiveBroker fans notifications of recorded views and likes out to other nodes,
+// e.g. through Redis or NATS. Without a broker, nodes pick up each other's changes
+// when they poll.
+type LiveBroker interface {
+	// Publish announces that a view or like of the normalized url was recorded on this node
+	Publish(ctx context.Context, url string) error
+	// Subscribe calls fn with every url announced by any node until ctx is done
+	Subscribe(ctx context.Context, fn func(url string)) error
+}
+
+// liveTopic holds the subscribers of a URL and the last state sent to them
+type liveTopic struct {
+	urlID int64
+	subs  map[chan types.LiveUpdate]struct{}
+	last  types.LiveUpdate
+	sent  bool
+
+	present  int32 // presence connections on this node
+	stored   int32 // presence connections last stored in the database
+	storedAt time.Time
+}
+
+// liveHub pushes the presence, reader count and view and like counts of URLs to subscribers.
+// It polls the database for every URL with subscribers, which also picks up views,
+// likes, engagement and presence connections of other nodes, and refreshes a URL
+// immediately when it is notified of a local change or of a change announced through
+// the broker. Presence connections of this node are stored in the database with an
+// expiry, so the connections of a node that went away stop counting.
+type liveHub struct {
+	ps     types.PersistenceService
+	broker LiveBroker
+	window time.Duration // readers are clients active within this window
+	nodeID int64
+	conns  *core.ConnLimiter
+
+	// active counts subscriptions that have not been cancelled, so shutdown can wait
+	// for streams and presence connections to say goodbye
+	active sync.WaitGroup
+
+	mu      sync.Mutex
+	topics  map[string]*liveTopic
+	count   int
+	stopped bool
+
+	notify chan string
+}
+
+// newLiveHub creates a live hub counting clients active within window as readers and
+// allowing maxConnsPerIP streams and presence connections per IP address. broker may be nil.
+func newLiveHub(ps types.PersistenceService, broker LiveBroker, window time.Duration, nodeID int64, maxConnsPerIP int) *liveHub {
+	return &liveHub{
+		ps:     ps,
+		broker: broker,
+		window: window,
+		nodeID: nodeID,
+		conns:  core.NewConnLimiter(maxConnsPerIP),
+		topics: make(map[string]*liveTopic),
+		notify: make(chan string, _LIVE_NOTIFY_BUFFER),
+	}
+}
+
+// Subscribe returns a channel receiving the state of url whenever it changes, starting
+// with the current state, for a stream from ip. Slow subscribers only receive the latest state.
+func (h *liveHub) Subscribe(url string, urlID int64, ip string) (<-chan types.LiveUpdate, func(), error) {
+	release, err := h.conns.Acquire(ip)
+	if err != nil {
+		return nil, nil, err
+	}
+	ch, _, cancel, err := h.subscribe(url, urlID)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			cancel()
+			release()
+		})
+	}
+	return ch, unsubscribe, nil
+}
+
+// Join subscribes a presence connection from ip, which counts as present until leave is called.
+func (h *liveHub) Join(url string, urlID int64, ip string) (<-chan types.LiveUpdate, func(), error) {
+	release, err := h.conns.Acquire(ip)
+	if err != nil {
+		return nil, nil, err
+	}
+	ch, topic, cancel, err := h.subscribe(url, urlID)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+
+	h.mu.Lock()
+	topic.present++
+	h.mu.Unlock()
+	h.enqueue(url)
+
+	var once sync.Once
+	leave := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			topic.present--
+			h.mu.Unlock()
+			cancel()
+			release()
+			h.enqueue(url)
+		})
+	}
+	return ch, leave, nil
+}
+
+func (h *liveHub) subscribe(url string, urlID int64) (chan types.LiveUpdate, *liveTopic, func(), error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.stopped {
+		return nil, nil, nil, ErrLiveStopped
+	}
+	if h.count >= _LIVE_MAX_SUBSCRIBERS {
+		return nil, nil, nil, ErrLiveFull
+	}
+
+	topic, ok := h.topics[url]
+	if !ok {
+		topic = &liveTopic{urlID: urlID, subs: make(map[chan types.LiveUpdate]struct{})}
+		h.topics[url] = topic
+	}
+	ch := make(chan types.LiveUpdate, 1)
+	topic.subs[ch] = struct{}{}
+	h.count++
+	h.active.Add(1)
+	if topic.sent {
+		ch <- topic.last
+	} else {
+		h.enqueue(url)
+	}
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			defer h.active.Done()
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			if _, ok := topic.subs[ch]; !ok {
+				return // closed by Run
+			}
+			delete(topic.subs, ch)
+			h.count--
+			h.removeIdle(url, topic)
+		})
+	}
+	return ch, topic, cancel, nil
+}
+
+// removeIdle forgets topic once it has neither subscribers nor stored presence.
+// h.mu must be held.
+func (h *liveHub) removeIdle(url string, topic *liveTopic) {
+	if len(topic.subs) == 0 && topic.present == 0 && topic.stored == 0 && h.topics[url] == topic {
+		delete(h.topics, url)
+	}
+}
+
+// Notify refreshes the subscribers of url and announces the change to other nodes.
+func (h *liveHub) Notify(url string) {
+	h.mu.Lock()
+	_, ok := h.topics[url]
+	h.mu.Unlock()
+	if ok {
+		h.enqueue(url)
+	}
+
+	if h.broker != nil {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := h.broker.Publish(ctx, url); err != nil {
+				log.Debug().Err(err).Str("url", url).Msg("failed to publish live notification")
+			}
+		}()
+	}
+}
+
+// enqueue schedules a refresh of url; refreshes are dropped when the queue is full
+// since the next poll picks them up.
+func (h *liveHub) enqueue(url string) {
+	select {
+	case h.notify <- url:
+	default:
+	}
+}
+
+// Run refreshes subscribed URLs until stop is closed, then closes all subscriber channels
+// and removes the presence connections of this node.
+func (h *liveHub) Run(stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if h.broker != nil {
+		go func() {
+			err := h.broker.Subscribe(ctx, func(url string) {
+				h.mu.Lock()
+				_, ok := h.topics[url]
+				h.mu.Unlock()
+				if ok {
+					h.enqueue(url)
+				}
+			})
+			if err != nil && ctx.Err() == nil {
+				log.Error().Err(err).Msg("live broker subscription failed")
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(_LIVE_POLL_INTERVAL)
+	defer ticker.Stop()
+
+	ticks := 0
+	for {
+		select {
+		case <-ticker.C:
+			h.mu.Lock()
+			urls := make([]string, 0, len(h.topics))
+			for url := range h.topics {
+				urls = append(urls, url)
+			}
+			h.mu.Unlock()
+			for _, url := range urls {
+				h.refresh(ctx, url)
+			}
+
+			if ticks++; ticks%_PRESENCE_GC_TICKS == 0 {
+				if err := h.ps.PresenceDeleteExpired(ctx, time.Now().UnixNano()); err != nil {
+					log.Error().Err(err).Msg("failed to delete expired presence")
+				}
+			}
+		case url := <-h.notify:
+			h.refresh(ctx, url)
+		case <-stop:
+			h.mu.Lock()
+			h.stopped = true
+			for _, topic := range h.topics {
+				for ch := range topic.subs {
+					close(ch)
+				}
+				topic.subs = nil
+			}
+			h.topics = make(map[string]*liveTopic)
+			h.count = 0
+			h.mu.Unlock()
+
+			if err := h.ps.PresenceDeleteNode(ctx, h.nodeID); err != nil {
+				log.Error().Err(err).Msg("failed to delete presence of this node")
+			}
+			return
+		}
+	}
+}
+
+// refresh stores the presence of url if it is due, then loads the state of url and sends it
+// to its subscribers if it changed
+func (h *liveHub) refresh(ctx context.Context, url string) {
+	now := time.Now()
+
+	h.mu.Lock()
+	topic, ok := h.topics[url]
+	if !ok {
+		h.mu.Unlock()
+		return
+	}
+	urlID, present := topic.urlID, topic.present
+	storePresence := present != topic.stored || (present > 0 && now.Sub(topic.storedAt) >= _PRESENCE_REFRESH)
+	h.mu.Unlock()
+
+	if storePresence {
+		err := h.ps.PresenceUpdate(ctx, urlID, h.nodeID, present, now.Add(_PRESENCE_TTL).UnixNano())
+		if err != nil {
+			log.Error().Err(err).Int64("url_id", urlID).Msg("failed to store presence")
+		} else {
+			h.mu.Lock()
+			topic.stored, topic.storedAt = present, now
+			h.removeIdle(url, topic)
+			h.mu.Unlock()
+		}
+	}
+
+	h.mu.Lock()
+	subscribed := len(topic.subs) > 0
+	h.mu.Unlock()
+	if !subscribed {
+		return
+	}
+
+	update, err := h.ps.LiveSnapshot(ctx, urlID, now.Add(-h.window).UnixNano())
+	if err != nil {
+		log.Error().Err(err).Int64("url_id", urlID).Msg("failed to load live snapshot")
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if topic.sent && topic.last == update {
+		return
+	}
+	topic.last = update
+	topic.sent = true
+	for ch := range topic.subs {
+		// Replace an unread update with the latest one
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- update
+	}
+}
+
+// Wait blocks until all subscriptions are cancelled or ctx is done
+func (h *liveHub) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.active.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
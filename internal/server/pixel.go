@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+)
+
+const (
+	_IP_DEDUP_GC_INTERVAL    = time.Hour
+	_IP_DEDUP_GC_LEASE_TTL   = time.Hour * 2
+	_IP_DEDUP_GC_BATCH_SIZE  = 10000
+	_IP_DEDUP_GC_MAX_BATCHES = 100
+)
+
+// ipDedupWorker periodically deletes tracking pixel dedup entries of past days.
+// Only the node holding the "view_ip_dedup_gc" lease deletes.
+func (g *Server) ipDedupWorker() {
+	ticker := time.NewTicker(_IP_DEDUP_GC_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !g.isLeader("view_ip_dedup_gc", _IP_DEDUP_GC_LEASE_TTL) {
+				continue
+			}
+
+			today := core.UTCDay(time.Now().UnixNano())
+			var total int64
+			for range _IP_DEDUP_GC_MAX_BATCHES {
+				deleted, err := g.ps.ViewIPDedupDeleteBefore(context.Background(), today, _IP_DEDUP_GC_BATCH_SIZE)
+				if err != nil {
+					log.Error().Err(err).Msg("failed to delete pixel dedup entries")
+					break
+				}
+				total += deleted
+				if deleted < _IP_DEDUP_GC_BATCH_SIZE {
+					break
+				}
+			}
+			log.Debug().Int64("deleted", total).Msg("pixel dedup gc completed")
+		case <-g.stopCh:
+			return
+		}
+	}
+}
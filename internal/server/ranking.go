@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+)
+
+const (
+	_RANKING_INTERVAL  = time.Minute * 10
+	_RANKING_LEASE_TTL = time.Minute * 30
+)
+
+// rankingWorker periodically rebuilds the top and trending leaderboards.
+// Only the node holding the "url_rankings" lease rebuilds.
+func (g *Server) rankingWorker() {
+	ticker := time.NewTicker(_RANKING_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !g.isLeader("url_rankings", _RANKING_LEASE_TTL) {
+				continue
+			}
+
+			start := time.Now()
+			ranked, err := core.RebuildRankings(context.Background(), g.ps, start)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to rebuild rankings")
+				continue
+			}
+			log.Debug().
+				Int("ranked", ranked).
+				Dur("duration", time.Since(start)).
+				Msg("rankings rebuilt")
+		case <-g.stopCh:
+			return
+		}
+	}
+}
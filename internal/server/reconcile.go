@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+	"telemetry.gosuda.org/telemetry/internal/types"
+)
+
+const _RECONCILE_BATCH_DELAY = time.Millisecond * 100
+
+// reconcileWorker periodically compares view and like counters with the raw rows
+// and logs drifted URLs, rewriting their counters if fix is set. Only the node
+// holding the "count_reconcile" lease runs the check.
+func (g *Server) reconcileWorker(interval time.Duration, fix bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !g.isLeader("count_reconcile", interval+time.Minute) {
+				continue
+			}
+
+			start := time.Now()
+			log.Debug().Bool("fix", fix).Msg("running count reconciliation")
+
+			result, err := core.ReconcileCounts(
+				context.Background(),
+				g.ps,
+				func() (int64, error) { return g.randflake.Generate() },
+				core.ReconcileOptions{BatchDelay: _RECONCILE_BATCH_DELAY, Fix: fix},
+				func(c types.CountCheck) {
+					log.Warn().
+						Str("url", c.URL).
+						Int64("view_count", c.ViewCount).
+						Int64("view_rows", c.ViewRows).
+						Int64("like_count", c.LikeCount).
+						Int64("like_rows", c.LikeRows).
+						Bool("fixed", c.Fixed).
+						Msg("count discrepancy")
+				},
+			)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to reconcile counts")
+				continue
+			}
+
+			log.Info().
+				Int64("checked", result.Checked).
+				Int64("discrepancies", result.Discrepancies).
+				Int64("fixed", result.Fixed).
+				Dur("duration", time.Since(start)).
+				Msg("count reconciliation completed")
+		case <-g.stopCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+)
+
+const (
+	_RELATED_INTERVAL  = time.Hour
+	_RELATED_LEASE_TTL = time.Hour * 2
+)
+
+// relatedWorker periodically rebuilds the related URL recommendations.
+// Only the node holding the "url_related" lease rebuilds.
+func (g *Server) relatedWorker(opts core.RelatedOptions) {
+	ticker := time.NewTicker(_RELATED_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !g.isLeader("url_related", _RELATED_LEASE_TTL) {
+				continue
+			}
+
+			start := time.Now()
+			pairs, err := core.RebuildRelated(context.Background(), g.ps, start, opts)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to rebuild related urls")
+				continue
+			}
+			log.Debug().
+				Int("pairs", pairs).
+				Dur("duration", time.Since(start)).
+				Msg("related urls rebuilt")
+		case <-g.stopCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"telemetry.gosuda.org/telemetry/internal/core"
+)
+
+const (
+	_RETENTION_INTERVAL    = time.Minute * 5
+	_RETENTION_LEASE_TTL   = time.Minute * 15
+	_RETENTION_BATCH_DELAY = time.Millisecond * 100
+)
+
+// retentionWorker periodically rolls up and deletes raw views older than the
+// retention period. Only the node holding the "view_retention" lease prunes.
+func (g *Server) retentionWorker(retention time.Duration) {
+	ticker := time.NewTicker(_RETENTION_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !g.isLeader("view_retention", _RETENTION_LEASE_TTL) {
+				continue
+			}
+
+			start := time.Now()
+			result, err := core.PruneViews(
+				context.Background(),
+				g.ps,
+				func() (int64, error) { return g.randflake.Generate() },
+				core.RetentionOptions{Retention: retention, BatchDelay: _RETENTION_BATCH_DELAY},
+			)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to prune views")
+				continue
+			}
+
+			log.Debug().
+				Int64("days_rolled_up", result.DaysRolledUp).
+				Int64("views_deleted", result.ViewsDeleted).
+				Dur("duration", time.Since(start)).
+				Msg("view retention completed")
+		case <-g.stopCh:
+			return
+		}
+	}
+}
@@ -2,25 +2,35 @@ package server
 
 import (
 	"context"
+	"crypto/hmac"
 	"crypto/sha256"
+	"encoding/binary"
 	"errors"
 	"net"
 	"net/http"
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/rs/zerolog/log"
 	"gosuda.org/randflake"
 	"telemetry.gosuda.org/telemetry/internal/api"
+	"telemetry.gosuda.org/telemetry/internal/core"
 	"telemetry.gosuda.org/telemetry/internal/types"
 )
 
 const (
 	_RANDFLAKE_RENEW_WINDOW = int64(time.Minute * 9)
 	_RANDFLAKE_SAFE_WINDOW  = int64(time.Second * 30)
+
+	_DEFAULT_ERROR_REPORT_LIMIT      = 10 // per client and minute
+	_DEFAULT_LIVE_WINDOW             = time.Minute
+	_DEFAULT_LIVE_CONNECTIONS_PER_IP = 10
+
+	_SHUTDOWN_TIMEOUT = 10 * time.Second
 )
 
 var (
@@ -30,12 +40,33 @@ var (
 type Server struct {
 	mux *httprouter.Router
 
-	ps     types.PersistenceService
-	stopCh chan struct{}
+	ps       types.PersistenceService
+	stopCh   chan struct{}
+	stopOnce sync.Once
+
+	httpServer *http.Server
 
 	lease        *types.RandflakeLease
 	randflake    *randflake.Generator
 	randflakeKey []byte
+
+	urlRules *core.URLCanonicalizer
+
+	errorLimiter *core.RateLimiter
+
+	// blocklist caches the active blocklist entries, reloaded by blocklistWorker
+	blocklist atomic.Pointer[core.Blocklist]
+
+	live *liveHub
+
+	// publicBaseURL is injected into the served browser SDK
+	publicBaseURL string
+
+	// ipHashKey keys the hashes of anonymous readers' IP addresses
+	ipHashKey []byte
+
+	// leaderID identifies this node in leader leases
+	leaderID int64
 }
 
 var _ types.InternalServiceProvider = (*serverServiceProvider)(nil)
@@ -53,9 +84,78 @@ func (g *serverServiceProvider) GenerateIDString() (string, error) {
 	return g.s.randflake.GenerateString()
 }
 
+func (g *serverServiceProvider) NormalizeURL(raw string) (string, error) {
+	return g.s.urlRules.NormalizeURL(raw)
+}
+
+func (g *serverServiceProvider) PublicBaseURL() string {
+	return g.s.publicBaseURL
+}
+
+func (g *serverServiceProvider) AnonymousIPHash(ip string, day int64) []byte {
+	mac := hmac.New(sha256.New, g.s.ipHashKey)
+	binary.Write(mac, binary.BigEndian, day)
+	mac.Write([]byte(ip))
+	return mac.Sum(nil)
+}
+
+func (g *serverServiceProvider) AllowErrorReport(clientID int64) bool {
+	return g.s.errorLimiter.Allow(clientID, time.Now())
+}
+
+func (g *serverServiceProvider) Blocked(c types.BlockCheck) bool {
+	return g.s.blocklist.Load().Blocked(c)
+}
+
+func (g *serverServiceProvider) BlocklistReload(ctx context.Context) error {
+	return g.s.loadBlocklist(ctx)
+}
+
+func (g *serverServiceProvider) LiveSubscribe(url string, urlID int64, ip string) (<-chan types.LiveUpdate, func(), error) {
+	return g.s.live.Subscribe(url, urlID, ip)
+}
+
+func (g *serverServiceProvider) LiveJoin(url string, urlID int64, ip string) (<-chan types.LiveUpdate, func(), error) {
+	return g.s.live.Join(url, urlID, ip)
+}
+
+func (g *serverServiceProvider) LiveNotify(url string) {
+	g.s.live.Notify(url)
+}
+
 type ServerConfig struct {
 	PersistenceService types.PersistenceService
 	RandflakeSecret    string `env:"RANDFLAKE_SECRET,required"`
+	URLRulesFile       string `env:"URL_RULES_FILE"`
+
+	// PublicBaseURL replaces the TELEMETRY_BASEURL of the served browser SDK, e.g. https://telemetry.gosuda.org
+	PublicBaseURL string `env:"PUBLIC_BASE_URL"`
+
+	// ReconcileInterval enables the periodic count reconciliation job when positive
+	ReconcileInterval time.Duration `env:"RECONCILE_INTERVAL"`
+	ReconcileFix      bool          `env:"RECONCILE_FIX"`
+
+	// ViewRetentionDays enables rolling up and deleting raw views older than this many days when positive
+	ViewRetentionDays int `env:"VIEW_RETENTION_DAYS"`
+
+	// ErrorReportLimit is the number of error reports accepted per client and minute, 10 if not set
+	ErrorReportLimit int `env:"ERROR_REPORT_LIMIT"`
+
+	// RelatedMinClients is the number of distinct clients that must have read two URLs before
+	// they are recommended for each other, 5 if not set
+	RelatedMinClients int64 `env:"RELATED_MIN_CLIENTS"`
+
+	// LiveWindow is how long a client counts as a live reader after its last view or engagement
+	// report, one minute if not set
+	LiveWindow time.Duration `env:"LIVE_WINDOW"`
+
+	// LiveConnectionsPerIP is the number of concurrent live streams and presence WebSockets accepted
+	// per IP address, 10 if not set
+	LiveConnectionsPerIP int `env:"LIVE_CONNECTIONS_PER_IP"`
+
+	// LiveBroker optionally fans live notifications out to other nodes immediately; without it
+	// nodes see each other's views and likes when they poll the database
+	LiveBroker LiveBroker
 }
 
 // NewServer creates a new server instance
@@ -64,12 +164,29 @@ func NewServer(c *ServerConfig) (*Server, error) {
 		ps:     c.PersistenceService,
 		mux:    httprouter.New(),
 		stopCh: make(chan struct{}),
+
+		publicBaseURL: strings.TrimSuffix(c.PublicBaseURL, "/"),
+	}
+	// created up front so that Shutdown never races with Serve
+	g.httpServer = &http.Server{Handler: &CORSServer{Handler: g.mux}}
+
+	urlRules, err := core.LoadURLCanonicalizer(c.URLRulesFile)
+	if err != nil {
+		log.Error().Err(err).Str("path", c.URLRulesFile).Msg("failed to load url rules")
+		return nil, err
 	}
+	g.urlRules = urlRules
+
+	errorReportLimit := c.ErrorReportLimit
+	if errorReportLimit <= 0 {
+		errorReportLimit = _DEFAULT_ERROR_REPORT_LIMIT
+	}
+	g.errorLimiter = core.NewRateLimiter(errorReportLimit, time.Minute)
 
 	ctx := context.Background()
 
 	log.Debug().Msg("pinging persistence service")
-	err := g.ps.Ping(ctx)
+	err = g.ps.Ping(ctx)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to ping persistence service")
 		return nil, err
@@ -96,6 +213,8 @@ func NewServer(c *ServerConfig) (*Server, error) {
 
 	randflakeSecretKey := sha256.Sum256([]byte(c.RandflakeSecret))
 	g.randflakeKey = randflakeSecretKey[:16]
+	ipHashKey := sha256.Sum256([]byte("view_ip_dedup:" + c.RandflakeSecret))
+	g.ipHashKey = ipHashKey[:]
 	rf, err := randflake.NewGenerator(
 		g.lease.NodeID,
 		g.lease.CreatedAt/int64(time.Second),
@@ -108,9 +227,48 @@ func NewServer(c *ServerConfig) (*Server, error) {
 	}
 	g.randflake = rf
 
+	g.leaderID, err = g.randflake.Generate()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to generate leader ID")
+		return nil, err
+	}
+
 	// Start the randflake worker
 	go g.randflakeWorker()
 
+	go g.ipDedupWorker()
+
+	go g.rankingWorker()
+
+	go g.relatedWorker(core.RelatedOptions{
+		MinClients: c.RelatedMinClients,
+		Window:     time.Duration(c.ViewRetentionDays) * 24 * time.Hour,
+	})
+
+	if err := g.loadBlocklist(ctx); err != nil {
+		log.Error().Err(err).Msg("failed to load blocklist")
+	}
+	go g.blocklistWorker()
+
+	liveWindow := c.LiveWindow
+	if liveWindow <= 0 {
+		liveWindow = _DEFAULT_LIVE_WINDOW
+	}
+	liveConnsPerIP := c.LiveConnectionsPerIP
+	if liveConnsPerIP <= 0 {
+		liveConnsPerIP = _DEFAULT_LIVE_CONNECTIONS_PER_IP
+	}
+	g.live = newLiveHub(g.ps, c.LiveBroker, liveWindow, g.leaderID, liveConnsPerIP)
+	go g.live.Run(g.stopCh)
+
+	if c.ReconcileInterval > 0 {
+		go g.reconcileWorker(c.ReconcileInterval, c.ReconcileFix)
+	}
+
+	if c.ViewRetentionDays > 0 {
+		go g.retentionWorker(time.Duration(c.ViewRetentionDays) * 24 * time.Hour)
+	}
+
 	is := &serverServiceProvider{
 		PersistenceService: g.ps,
 		s:                  g,
@@ -121,6 +279,24 @@ func NewServer(c *ServerConfig) (*Server, error) {
 	return g, nil
 }
 
+// NewLeasedGenerator creates a randflake generator backed by a new node lease.
+// The lease is never extended, so it is only suitable for short lived
+// processes such as maintenance commands.
+func NewLeasedGenerator(ctx context.Context, ps types.PersistenceService, secret string) (*randflake.Generator, error) {
+	lease, err := ps.RandflakeLeaseCreate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key := sha256.Sum256([]byte(secret))
+	return randflake.NewGenerator(
+		lease.NodeID,
+		lease.CreatedAt/int64(time.Second),
+		(lease.ExpiresAt-_RANDFLAKE_SAFE_WINDOW)/int64(time.Second),
+		key[:16],
+	)
+}
+
 func (g *Server) randflakeWorker() {
 	ticker := time.NewTicker(time.Second * 30)
 	defer ticker.Stop()
@@ -258,10 +434,32 @@ func (s *CORSServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.Handler.ServeHTTP(w, r)
 }
 
+// Serve accepts connections on ln until Shutdown is called. It returns
+// http.ErrServerClosed after Shutdown, even if it is called afterwards.
 func (g *Server) Serve(ln net.Listener) error {
-	return http.Serve(ln, &CORSServer{Handler: g.mux})
+	select {
+	case <-g.stopCh:
+		ln.Close()
+		return http.ErrServerClosed
+	default:
+	}
+	return g.httpServer.Serve(ln)
 }
 
+// Shutdown stops the background workers, closes live streams and presence connections
+// with code 1012 so clients reconnect to another node, and waits up to
+// _SHUTDOWN_TIMEOUT for in-flight requests to finish.
 func (g *Server) Shutdown() {
-	// Shutdown logic can be implemented here
+	g.stopOnce.Do(func() { close(g.stopCh) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), _SHUTDOWN_TIMEOUT)
+	defer cancel()
+
+	if err := g.httpServer.Shutdown(ctx); err != nil {
+		log.Error().Err(err).Msg("failed to shut down http server")
+	}
+	// Hijacked WebSocket connections are not tracked by the http server
+	if err := g.live.Wait(ctx); err != nil {
+		log.Error().Err(err).Msg("live connections did not close in time")
+	}
 }
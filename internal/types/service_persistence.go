@@ -2,6 +2,7 @@ package types
 
 import (
 	"context"
+	"time"
 )
 
 type PersistenceService interface {
@@ -17,18 +18,127 @@ type PersistenceService interface {
 	ClientVerifyToken(ctx context.Context, clientID int64, token string) (bool, error)
 	ClientRegister(ctx context.Context, id int64, token string) error
 
+	// Device-related methods
+	ClientDeviceUpsert(ctx context.Context, id int64, clientID int64, device DeviceInfo) error
+	DeviceStatsByUrl(ctx context.Context, urlID int64) ([]DeviceStatsEntry, error)
+
 	// URL-related methods
 	UrlLookupByUrl(ctx context.Context, url string) (Url, error)
-	UrlInsert(ctx context.Context, id int64, url string) error
+	UrlGetOrCreate(ctx context.Context, id int64, url string) (int64, error)
+	UrlAliasCreate(ctx context.Context, id int64, alias string, urlID int64) error
+	UrlAliasList(ctx context.Context, urlID int64) ([]UrlAlias, error)
+	UrlMerge(ctx context.Context, fromID int64, intoID int64, aliasID int64) (UrlMergeResult, error)
+	UrlListDuplicates(ctx context.Context) ([]string, error)
+	UrlListByUrl(ctx context.Context, url string) ([]Url, error)
+	UrlBackfillHashes(ctx context.Context) (int64, error)
 
 	// View-related methods
-	ViewInsertWithCount(ctx context.Context, id int64, urlID int64, clientID int64, countID int64) error
+	// urlID and countID are used in case the URL record or the count row has to be created.
+	// The insert methods return the canonical URL, which differs from the given one for aliases.
+	ViewInsertWithCount(ctx context.Context, view ViewEvent, urlID int64, countID int64) (string, error)
 	ViewCountLookup(ctx context.Context, urlID int64) (ViewCount, error)
+	// ViewInsertAnonymous records a view without a client at most once per URL, ipHash and day
+	ViewInsertAnonymous(ctx context.Context, view ViewEvent, ipHash []byte, day int64, urlID int64, countID int64) (string, bool, error)
+	ViewIPDedupDeleteBefore(ctx context.Context, day int64, limit int32) (int64, error)
+	// ViewDailyCounts returns the views of urlID per UTC day, including rolled up days
+	ViewDailyCounts(ctx context.Context, urlID int64, fromDay int64, days int) ([]int64, error)
+
+	// Event batches are written in one transaction; the result reports what happened to each
+	// event. New custom event names are registered in the same transaction and rejected if
+	// their site already has maxNames other names or their client already introduced
+	// maxClientNames names.
+	EventBatchInsert(ctx context.Context, events []ClientEvent, maxNames int64, maxClientNames int64) ([]EventInsertResult, error)
+	EventStatsByUrl(ctx context.Context, urlID int64, from int64, to int64, limit int32) ([]EventStatsEntry, error)
+	// site is a normalized host, empty for all sites
+	EventStatsBySite(ctx context.Context, site string, from int64, to int64, limit int32) ([]EventStatsEntry, error)
+
+	// Engagement-related methods
+	EngagementRecord(ctx context.Context, viewID int64, clientID int64, activeMs int64, maxScroll int32) (bool, error)
+	EngagementStatsByUrl(ctx context.Context, urlID int64, from int64, to int64) (EngagementStats, error)
+
+	// Web vitals; urlID is used in case the URL record has to be created
+	VitalInsert(ctx context.Context, url string, clientID int64, urlID int64, measurements []VitalMeasurement) error
+	VitalBucketsByUrl(ctx context.Context, urlID int64, from int64, to int64) ([]VitalBucketCount, error)
+
+	// Client error reports; groupID and urlID are used in case the group or the URL record has to be created
+	ErrorReportInsert(ctx context.Context, groupID int64, urlID int64, report ErrorReport) error
+	ErrorGroupList(ctx context.Context, site string, from int64, to int64, limit int32, maxURLs int) ([]ErrorGroup, error)
+
+	// Retention: raw views of a UTC day are rolled up into daily aggregates before they are deleted
+	ViewOldestBefore(ctx context.Context, before int64) (int64, error)
+	ViewDailyAggregate(ctx context.Context, day int64) ([]ViewRollup, error)
+	ViewRollupDone(ctx context.Context, day int64) (bool, error)
+	ViewRollupInsert(ctx context.Context, day int64, rollups []ViewRollup) (bool, error)
+	ViewDeleteBefore(ctx context.Context, before int64, limit int32) (int64, error)
+
+	// Leader election for background jobs
+	LeaderLeaseAcquire(ctx context.Context, name string, holder int64, ttl time.Duration) (bool, error)
+	LeaderLeaseRelease(ctx context.Context, name string, holder int64) error
+
+	// Referrer-related methods
+	ReferrerStatsByUrl(ctx context.Context, urlID int64, from int64, to int64, limit int32) ([]ReferrerStatsEntry, error)
+
+	// Campaign-related methods; site is a normalized host, empty for all sites
+	CampaignStats(ctx context.Context, site string, from int64, to int64, limit int32, pagesPerCampaign int32) ([]CampaignStatsEntry, error)
+	CampaignLandingPages(ctx context.Context, site string, from int64, to int64, limit int32) ([]CampaignLandingPage, error)
 
 	// Like-related methods (mirrors view implementation; likes are read-heavy so no combined write+get helper on client)
-	LikeInsertWithCount(ctx context.Context, id int64, urlID int64, clientID int64, countID int64) error
+	LikeInsertWithCount(ctx context.Context, id int64, url string, clientID int64, urlID int64, countID int64) (string, error)
 	LikeCountLookup(ctx context.Context, urlID int64) (LikeCount, error)
 
+	// Count reconciliation; the reconcile methods return false if the counter changed concurrently
+	CountReconcileBatch(ctx context.Context, afterID int64, limit int32) ([]CountCheck, error)
+	ViewCountReconcile(ctx context.Context, id int64, urlID int64, expected int64, actual int64) (bool, error)
+	LikeCountReconcile(ctx context.Context, id int64, urlID int64, expected int64, actual int64) (bool, error)
+
+	// Leaderboards; metric and window are Ranking* codes. RankingCounts returns counts since from,
+	// or the counters if from is 0. RankingDecayed sums exp((created_at-now)/tau) since from.
+	RankingCounts(ctx context.Context, metric int32, from int64) ([]RankingScore, error)
+	RankingDecayed(ctx context.Context, metric int32, now int64, tau int64, from int64) ([]RankingScore, error)
+	RankingReplace(ctx context.Context, metric int32, window int32, scores []RankingScore, computedAt int64) error
+	RankingTop(ctx context.Context, metric int32, window int32, site string, limit int32) ([]RankingEntry, int64, error)
+
+	// Related URLs; visits only cover views of known clients since from. RelatedVisits returns the
+	// visits of the next clients after afterClientID and the last client returned, 0 if there are none.
+	RelatedVisits(ctx context.Context, from int64, afterClientID int64, clients int32) ([]RelatedVisit, int64, error)
+	RelatedReplace(ctx context.Context, pairs []RelatedPair, computedAt int64) error
+	RelatedByUrl(ctx context.Context, urlID int64, site string, limit int32) ([]RelatedEntry, error)
+
+	// LiveSnapshot returns the counts of urlID and the number of clients active on it since the given time
+	LiveSnapshot(ctx context.Context, urlID int64, since int64) (LiveUpdate, error)
+	PresenceUpdate(ctx context.Context, urlID int64, nodeID int64, connections int32, expiresAt int64) error
+	PresenceDeleteNode(ctx context.Context, nodeID int64) error
+	PresenceDeleteExpired(ctx context.Context, now int64) error
+
+	// Admin API keys; lookups return false if there is no such key
+	ApiKeyCreate(ctx context.Context, id int64, name string, keyHash []byte, scopes []string) (ApiKey, error)
+	ApiKeyLookupByHash(ctx context.Context, keyHash []byte) (ApiKey, bool, error)
+	ApiKeyLookupByID(ctx context.Context, id int64) (ApiKey, bool, error)
+	ApiKeyList(ctx context.Context) ([]ApiKey, error)
+	ApiKeyRevoke(ctx context.Context, id int64) (bool, error)
+	ApiKeyTouch(ctx context.Context, id int64) error
+
+	// Moderation; purges recompute the counter of the URL in the same transaction.
+	// Displayed counts include the count offset of the URL.
+	ViewPurge(ctx context.Context, filter PurgeFilter) (PurgeResult, error)
+	LikePurge(ctx context.Context, filter PurgeFilter) (PurgeResult, error)
+	ViewCountDisplay(ctx context.Context, urlID int64) (int64, error)
+	LikeCountDisplay(ctx context.Context, urlID int64) (int64, error)
+	CountOffsetLookup(ctx context.Context, urlID int64) (CountOffset, error)
+	CountOffsetSet(ctx context.Context, urlID int64, offset CountOffset) error
+
+	// Blocklist; BlocklistRemove returns false if there is no such entry
+	BlocklistAdd(ctx context.Context, entry BlockEntry) (BlockEntry, error)
+	BlocklistRemove(ctx context.Context, id int64) (BlockEntry, bool, error)
+	BlocklistList(ctx context.Context) ([]BlockEntry, error)
+	BlocklistActive(ctx context.Context, now int64) ([]BlockEntry, error)
+	BlocklistDeleteExpired(ctx context.Context, now int64) (int64, error)
+	BlocklistFingerprintClients(ctx context.Context, fingerprints []string) ([]int64, error)
+
+	// Audit log; events are only ever appended
+	AuditRecord(ctx context.Context, event AuditEvent) error
+	AuditList(ctx context.Context, filter AuditFilter, limit int32) ([]AuditEvent, error)
+
 	// Bulk counts: return view and like counts for a list of normalized URLs
 	BulkCountsByUrls(ctx context.Context, urls []string) ([]BulkCountEntry, error)
 }
@@ -1,6 +1,42 @@
 package types
 
+import "context"
+
 type ServerService interface {
 	GenerateID() (int64, error)
 	GenerateIDString() (string, error)
+
+	// NormalizeURL canonicalizes a URL using the configured per-site rules
+	NormalizeURL(raw string) (string, error)
+
+	// PublicBaseURL is the URL the API is reachable at from browsers, empty for the SDK default
+	PublicBaseURL() string
+
+	// AnonymousIPHash returns a keyed hash of ip for deduplicating anonymous views on day,
+	// which cannot be reversed without the server secret
+	AnonymousIPHash(ip string, day int64) []byte
+
+	// AllowErrorReport rate limits client error reports per client
+	AllowErrorReport(clientID int64) bool
+
+	// Blocked reports whether a client submission is blocked. Blocked submissions are
+	// answered as usual but not recorded.
+	Blocked(c BlockCheck) bool
+
+	// BlocklistReload reloads the blocklist of this node after it was changed; other
+	// nodes pick changes up within 30 seconds
+	BlocklistReload(ctx context.Context) error
+
+	// LiveSubscribe subscribes a stream from ip to live updates of the normalized url with the
+	// given ID. The channel is closed when the server stops; cancel must be called when the
+	// subscriber goes away. It fails with core.ErrTooManyConnections if ip has too many streams
+	// and presence connections.
+	LiveSubscribe(url string, urlID int64, ip string) (updates <-chan LiveUpdate, cancel func(), err error)
+
+	// LiveJoin is LiveSubscribe for a presence connection from ip, which is counted as present
+	// until leave is called.
+	LiveJoin(url string, urlID int64, ip string) (updates <-chan LiveUpdate, leave func(), err error)
+
+	// LiveNotify tells live subscribers of the normalized url that a view or like was recorded
+	LiveNotify(url string)
 }
@@ -0,0 +1,31 @@
+package types
+
+// Scopes of admin API keys
+const (
+	ScopeReadStats       = "read:stats"
+	ScopeWriteModeration = "write:moderation"
+	ScopeAdmin           = "admin" // grants every scope
+)
+
+// ApiKey is an admin API key without its secret
+type ApiKey struct {
+	ID         int64    `json:"id,string"`
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	CreatedAt  int64    `json:"created_at"`             // Unix nanoseconds
+	LastUsedAt int64    `json:"last_used_at,omitempty"` // Unix nanoseconds, updated at most once a minute
+	RevokedAt  int64    `json:"revoked_at,omitempty"`   // Unix nanoseconds
+}
+
+// HasScope reports whether the key is active and grants scope
+func (k ApiKey) HasScope(scope string) bool {
+	if k.RevokedAt != 0 {
+		return false
+	}
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package types
+
+import "encoding/json"
+
+// Audit event actions
+const (
+	AuditViewsPurge     = "views.purge"
+	AuditLikesPurge     = "likes.purge"
+	AuditCountOffsetSet = "count_offset.set"
+
+	AuditBlocklistAdd    = "blocklist.add"
+	AuditBlocklistRemove = "blocklist.remove"
+
+	AuditApiKeyCreate = "api_key.create"
+	AuditApiKeyRevoke = "api_key.revoke"
+	AuditApiKeyRotate = "api_key.rotate"
+
+	AuditUrlAlias  = "url.alias"
+	AuditUrlMerge  = "url.merge"
+	AuditCountsFix = "counts.fix"
+)
+
+// AuditActorMaintenance is the actor of events recorded by maintenance commands
+// and background workers rather than through the admin API
+const AuditActorMaintenance int64 = 0
+
+// AuditEvent records an admin action. Before and After hold the JSON state of the
+// target before and after the action. Events are never updated or deleted.
+type AuditEvent struct {
+	ID         int64           `json:"id,string"`
+	ActorKeyID int64           `json:"actor_key_id,string"`
+	Action     string          `json:"action"`
+	Target     string          `json:"target"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	CreatedAt  int64           `json:"created_at"` // Unix nanoseconds
+}
+
+// AuditFilter selects audit events created in [From, To), newest first. Empty
+// Action and Target match any value. Events at or after the cursor position
+// (CursorCreatedAt, CursorID) are skipped.
+type AuditFilter struct {
+	From            int64
+	To              int64
+	AnyActor        bool
+	ActorKeyID      int64
+	Action          string
+	Target          string
+	CursorCreatedAt int64
+	CursorID        int64
+}
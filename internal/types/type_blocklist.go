@@ -0,0 +1,64 @@
+package types
+
+import "errors"
+
+// BlockKind is what a blocklist entry matches, stored as its numeric code and
+// written as its name in JSON
+type BlockKind int32
+
+const (
+	BlockClient      BlockKind = 1 // randflake client id
+	BlockFingerprint BlockKind = 2 // browser fingerprint hash sent to /client/checkin
+	BlockNetwork     BlockKind = 3 // IP address or CIDR network
+	BlockUserAgent   BlockKind = 4 // case-insensitive regular expression on the User-Agent header
+)
+
+var ErrInvalidBlockKind = errors.New("types: invalid block kind")
+
+var _block_kind_names = map[BlockKind]string{
+	BlockClient:      "client",
+	BlockFingerprint: "fingerprint",
+	BlockNetwork:     "network",
+	BlockUserAgent:   "user_agent",
+}
+
+func (k BlockKind) String() string {
+	return _block_kind_names[k]
+}
+
+func (k BlockKind) MarshalText() ([]byte, error) {
+	name, ok := _block_kind_names[k]
+	if !ok {
+		return nil, ErrInvalidBlockKind
+	}
+	return []byte(name), nil
+}
+
+func (k *BlockKind) UnmarshalText(text []byte) error {
+	for kind, name := range _block_kind_names {
+		if name == string(text) {
+			*k = kind
+			return nil
+		}
+	}
+	return ErrInvalidBlockKind
+}
+
+// BlockEntry is an entry of the blocklist
+type BlockEntry struct {
+	ID        int64     `json:"id,string"`
+	Kind      BlockKind `json:"kind"`
+	Value     string    `json:"value"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedBy int64     `json:"created_by,string"`    // API key
+	CreatedAt int64     `json:"created_at"`           // Unix nanoseconds
+	ExpiresAt int64     `json:"expires_at,omitempty"` // Unix nanoseconds, 0 for never
+}
+
+// BlockCheck describes the sender of a client submission
+type BlockCheck struct {
+	ClientID    int64
+	Fingerprint string // only known to /client/checkin
+	IP          string
+	UserAgent   string
+}
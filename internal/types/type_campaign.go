@@ -0,0 +1,35 @@
+package types
+
+// Campaign holds the UTM parameters attached to a viewed URL
+type Campaign struct {
+	Source  string `json:"source"`  // utm_source
+	Medium  string `json:"medium"`  // utm_medium
+	Name    string `json:"name"`    // utm_campaign
+	Term    string `json:"term"`    // utm_term
+	Content string `json:"content"` // utm_content
+}
+
+// CampaignLandingPage is the number of campaign views of a single landing page
+type CampaignLandingPage struct {
+	URL   string `json:"url"`
+	Count int64  `json:"count"`
+}
+
+// CampaignStatsEntry is the number of views attributed to a single campaign and
+// its top landing pages
+type CampaignStatsEntry struct {
+	Source       string                `json:"source"`
+	Medium       string                `json:"medium"`
+	Name         string                `json:"name"`
+	Count        int64                 `json:"count"`
+	LandingPages []CampaignLandingPage `json:"landing_pages"`
+}
+
+// CampaignStatsResponse is returned by the campaign stats API
+type CampaignStatsResponse struct {
+	Site         string                `json:"site,omitempty"`
+	From         int64                 `json:"from"` // Unix nanoseconds, inclusive
+	To           int64                 `json:"to"`   // Unix nanoseconds, exclusive
+	Campaigns    []CampaignStatsEntry  `json:"campaigns"`
+	LandingPages []CampaignLandingPage `json:"landing_pages"`
+}
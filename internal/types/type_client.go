@@ -4,6 +4,7 @@ import "telemetry.gosuda.org/telemetry/internal/persistence/database"
 
 type ClientIdentifier = database.ClientIdentifier
 type Url = database.Url
+type UrlAlias = database.UrlAlias
 type ViewCount = database.ViewCount
 type Like = database.Like
 type LikeCount = database.LikeCount
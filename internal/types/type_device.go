@@ -0,0 +1,43 @@
+package types
+
+// Device classes reported by DeviceInfo.DeviceClass
+const (
+	DeviceClassDesktop = "desktop"
+	DeviceClassMobile  = "mobile"
+	DeviceClassTablet  = "tablet"
+	DeviceClassBot     = "bot"
+	DeviceClassUnknown = "unknown"
+)
+
+// DeviceInfo holds the normalized dimensions parsed from a client's
+// User-Agent string and User-Agent Client Hints
+type DeviceInfo struct {
+	Browser        string `json:"browser"`         // Browser family (e.g. "Chrome", "Firefox")
+	BrowserVersion string `json:"browser_version"` // Major browser version
+	OS             string `json:"os"`              // Operating system family
+	DeviceClass    string `json:"device_class"`    // One of the DeviceClass* constants
+	Mobile         bool   `json:"mobile"`          // DeviceClass is mobile, decided by UA-CH when present
+}
+
+// DeviceStatsEntry is a single row of the per-URL device breakdown
+type DeviceStatsEntry struct {
+	DeviceInfo
+	Count int64 `json:"count"`
+}
+
+// DimensionCount is a count of views for one value of a dimension
+type DimensionCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// DeviceStatsResponse is returned by the device stats API
+type DeviceStatsResponse struct {
+	URL             string           `json:"url"`
+	Total           int64            `json:"total"`
+	Browsers        []DimensionCount `json:"browsers"`
+	BrowserVersions []DimensionCount `json:"browser_versions"`
+	OS              []DimensionCount `json:"os"`
+	DeviceClasses   []DimensionCount `json:"device_classes"`
+	Mobile          []DimensionCount `json:"mobile"`
+}
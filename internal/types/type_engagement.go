@@ -0,0 +1,22 @@
+package types
+
+// ScrollDepthBucket is the number of engaged views whose maximum scroll depth fell into a range
+type ScrollDepthBucket struct {
+	Range string `json:"range"` // e.g. "25-49", in percent
+	Count int64  `json:"count"`
+}
+
+// EngagementStats summarizes the reading time and scroll depth of the views of a URL
+type EngagementStats struct {
+	Views          int64               `json:"views"`            // views with at least one engagement report
+	MedianActiveMs int64               `json:"median_active_ms"` // median active reading time in milliseconds
+	ScrollDepth    []ScrollDepthBucket `json:"scroll_depth"`
+}
+
+// EngagementStatsResponse is returned by the engagement stats API
+type EngagementStatsResponse struct {
+	URL  string `json:"url"`
+	From int64  `json:"from"` // Unix nanoseconds, inclusive
+	To   int64  `json:"to"`   // Unix nanoseconds, exclusive
+	EngagementStats
+}
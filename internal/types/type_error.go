@@ -0,0 +1,45 @@
+package types
+
+// ErrorReport is a validated client-side JavaScript error
+type ErrorReport struct {
+	Fingerprint []byte // SHA-256 of the normalized error, see core.NewErrorReport
+	Message     string
+	Stack       string
+	Source      string // URL of the script that threw
+	Line        int32
+	Column      int32
+	UserAgent   string
+	URL         string // normalized URL of the page the error happened on
+}
+
+// ErrorGroupURL is a page an error group was reported on
+type ErrorGroupURL struct {
+	URL      string `json:"url"`
+	Count    int64  `json:"count"`
+	LastSeen int64  `json:"last_seen"` // Unix nanoseconds
+}
+
+// ErrorGroup is a group of error reports with the same fingerprint.
+// The message, stack and location are those of the first report.
+type ErrorGroup struct {
+	ID          int64           `json:"id,string"`
+	Fingerprint string          `json:"fingerprint"` // hex encoded
+	Message     string          `json:"message"`
+	Stack       string          `json:"stack"`
+	Source      string          `json:"source"`
+	Line        int32           `json:"line"`
+	Column      int32           `json:"column"`
+	UserAgent   string          `json:"user_agent"`
+	Count       int64           `json:"count"`
+	FirstSeen   int64           `json:"first_seen"` // Unix nanoseconds
+	LastSeen    int64           `json:"last_seen"`  // Unix nanoseconds
+	URLs        []ErrorGroupURL `json:"urls"`
+}
+
+// ErrorGroupsResponse is returned by the error groups API
+type ErrorGroupsResponse struct {
+	Site   string       `json:"site,omitempty"`
+	From   int64        `json:"from"` // Unix nanoseconds, inclusive
+	To     int64        `json:"to"`   // Unix nanoseconds, exclusive
+	Groups []ErrorGroup `json:"groups"`
+}
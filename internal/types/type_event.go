@@ -0,0 +1,67 @@
+package types
+
+// Types of events accepted by POST /client/events
+const (
+	EventTypeView   = "view"
+	EventTypeLike   = "like"
+	EventTypeCustom = "custom"
+)
+
+// ClientEvent is a validated event of a batch sent by a client
+type ClientEvent struct {
+	Type      string
+	ID        int64
+	URL       string // normalized URL
+	ClientID  int64
+	CreatedAt int64 // client timestamp in unix nanoseconds
+
+	Referrer Referrer // view events
+	Campaign Campaign // view events
+
+	Name       string // custom events
+	Properties string // custom events, JSON object
+	Site       string // custom events, host of URL whose event name caps apply
+
+	UrlID   int64 // used in case the URL record has to be created
+	CountID int64 // used in case the count row has to be created
+}
+
+// EventInsertStatus tells whether EventBatchInsert recorded an event
+type EventInsertStatus int
+
+const (
+	EventInserted     EventInsertStatus = iota
+	EventDuplicate                      // a like the client already gave
+	EventNameRejected                   // a custom event whose new name exceeds the name caps
+)
+
+// EventInsertResult is what EventBatchInsert did with a single event. URL is the canonical
+// URL the event was recorded for, which differs from the event URL if that is an alias.
+type EventInsertResult struct {
+	Status EventInsertStatus
+	URL    string
+}
+
+// EventResult is the outcome of a single event of a batch
+type EventResult struct {
+	Index  int    `json:"index"`
+	Status string `json:"status"` // "ok", "duplicate" or "error"
+	Error  string `json:"error,omitempty"`
+}
+
+// EventStatsEntry is the number of custom events with a single name
+type EventStatsEntry struct {
+	Name    string `json:"name"`
+	Count   int64  `json:"count"`
+	Clients int64  `json:"clients"`        // distinct clients
+	URLs    int64  `json:"urls,omitempty"` // distinct URLs, site stats only
+}
+
+// EventStatsResponse is returned by the event stats API for a URL or a site
+type EventStatsResponse struct {
+	URL     string            `json:"url,omitempty"`
+	Site    string            `json:"site,omitempty"`
+	From    int64             `json:"from"` // Unix nanoseconds, inclusive
+	To      int64             `json:"to"`   // Unix nanoseconds, exclusive
+	Results []EventStatsEntry `json:"results"`
+}
@@ -0,0 +1,9 @@
+package types
+
+// LiveUpdate is the live state of a URL pushed to subscribers
+type LiveUpdate struct {
+	Present int64 `json:"present"` // open presence connections on all nodes
+	Readers int64 `json:"readers"` // distinct clients that viewed the URL or reported engagement recently
+	Views   int64 `json:"views"`
+	Likes   int64 `json:"likes"`
+}
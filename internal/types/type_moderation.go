@@ -0,0 +1,34 @@
+package types
+
+import "errors"
+
+// ErrPurgeRolledUp is returned when a view purge covers days whose views were rolled up
+var ErrPurgeRolledUp = errors.New("types: purge range covers rolled up days")
+
+// PurgeFilter selects the views or likes of a URL to delete
+type PurgeFilter struct {
+	UrlID     int64
+	From      int64   // Unix nanoseconds, inclusive
+	To        int64   // Unix nanoseconds, exclusive
+	ClientIDs []int64 // only rows of these clients; all clients if empty
+
+	// SkipRolledUp moves From of a view purge past the latest rolled up day instead of
+	// failing with ErrPurgeRolledUp
+	SkipRolledUp bool
+}
+
+// PurgeResult reports the purged range, the rows deleted by a purge and the recomputed counter
+type PurgeResult struct {
+	URL         string `json:"url"`
+	From        int64  `json:"from"` // Unix nanoseconds, inclusive
+	To          int64  `json:"to"`   // Unix nanoseconds, exclusive
+	Deleted     int64  `json:"deleted"`
+	CountBefore int64  `json:"count_before"`
+	CountAfter  int64  `json:"count_after"`
+}
+
+// CountOffset is added to the stored view and like counts of a URL wherever they are displayed
+type CountOffset struct {
+	ViewOffset int64 `json:"view_offset"`
+	LikeOffset int64 `json:"like_offset"`
+}
@@ -0,0 +1,40 @@
+package types
+
+// Leaderboard metrics, stored as their numeric code
+const (
+	RankingViews    int32 = 1
+	RankingLikes    int32 = 2
+	RankingTrending int32 = 3
+)
+
+// Leaderboard windows, stored as their numeric code. Trending is only ranked all time
+// since its decay already favors recent activity.
+const (
+	RankingAllTime int32 = 0
+	Ranking24h     int32 = 1
+	Ranking7d      int32 = 2
+	Ranking30d     int32 = 3
+)
+
+// RankingScore is the score of a URL on a leaderboard
+type RankingScore struct {
+	UrlID int64
+	URL   string
+	Score float64
+}
+
+// RankingEntry is a ranked URL
+type RankingEntry struct {
+	Rank  int     `json:"rank"`
+	URL   string  `json:"url"`
+	Score float64 `json:"score"`
+}
+
+// TopResponse lists the highest ranked URLs of a leaderboard
+type TopResponse struct {
+	Site       string         `json:"site,omitempty"`
+	Metric     string         `json:"metric"`
+	Window     string         `json:"window"`
+	ComputedAt int64          `json:"computed_at"` // Unix nanoseconds of the last rebuild, 0 if no URL is ranked
+	Entries    []RankingEntry `json:"entries"`
+}
@@ -0,0 +1,24 @@
+package types
+
+// CountCheck compares the stored view and like counters of a URL with the number of raw rows
+type CountCheck struct {
+	UrlID     int64  `json:"url_id"`
+	URL       string `json:"url"`
+	ViewCount int64  `json:"view_count"` // value of view_counts.count, 0 if missing
+	ViewRows  int64  `json:"view_rows"`  // number of rows in views plus rolled up views
+	LikeCount int64  `json:"like_count"` // value of like_counts.count, 0 if missing
+	LikeRows  int64  `json:"like_rows"`  // number of rows in likes
+	Fixed     bool   `json:"fixed"`
+}
+
+// Drifted reports whether any counter differs from its raw rows
+func (c CountCheck) Drifted() bool {
+	return c.ViewCount != c.ViewRows || c.LikeCount != c.LikeRows
+}
+
+// ReconcileResult summarizes a count reconciliation run
+type ReconcileResult struct {
+	Checked       int64 `json:"checked"`
+	Discrepancies int64 `json:"discrepancies"`
+	Fixed         int64 `json:"fixed"`
+}
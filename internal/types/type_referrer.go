@@ -0,0 +1,30 @@
+package types
+
+// Referrer classes reported by Referrer.Class
+const (
+	ReferrerClassDirect   = "direct"
+	ReferrerClassSearch   = "search"
+	ReferrerClassSocial   = "social"
+	ReferrerClassInternal = "internal"
+	ReferrerClassOther    = "other"
+)
+
+// Referrer is the normalized traffic source of a page view
+type Referrer struct {
+	Source string `json:"source"` // Normalized source host, empty for direct traffic
+	Class  string `json:"class"`  // One of the ReferrerClass* constants
+}
+
+// ReferrerStatsEntry is the number of views attributed to a single source
+type ReferrerStatsEntry struct {
+	Referrer
+	Count int64 `json:"count"`
+}
+
+// ReferrerStatsResponse is returned by the referrer stats API
+type ReferrerStatsResponse struct {
+	URL     string               `json:"url"`
+	From    int64                `json:"from"` // Unix nanoseconds, inclusive
+	To      int64                `json:"to"`   // Unix nanoseconds, exclusive
+	Results []ReferrerStatsEntry `json:"results"`
+}
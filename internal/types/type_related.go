@@ -0,0 +1,27 @@
+package types
+
+// RelatedPair is a pair of URLs read by the same clients
+type RelatedPair struct {
+	UrlID        int64
+	RelatedUrlID int64
+	Clients      int64 // distinct clients that read both URLs
+	Score        float64
+}
+
+// RelatedVisit is a URL viewed by a client
+type RelatedVisit struct {
+	ClientID int64
+	UrlID    int64
+}
+
+// RelatedEntry is a URL recommended for another URL
+type RelatedEntry struct {
+	URL   string  `json:"url"`
+	Score float64 `json:"score"` // cosine similarity of the sets of clients that read both URLs
+}
+
+// RelatedResponse lists the URLs most often read by readers of URL
+type RelatedResponse struct {
+	URL     string         `json:"url"`
+	Related []RelatedEntry `json:"related"`
+}
@@ -0,0 +1,16 @@
+package types
+
+// ViewRollup is the daily aggregate of the raw views of a URL
+type ViewRollup struct {
+	ID            int64 `json:"id"`
+	UrlID         int64 `json:"url_id"`
+	Day           int64 `json:"day"` // start of the UTC day in unix nanoseconds
+	Views         int64 `json:"views"`
+	UniqueClients int64 `json:"unique_clients"`
+}
+
+// RetentionResult summarizes a retention pass
+type RetentionResult struct {
+	DaysRolledUp int64 `json:"days_rolled_up"`
+	ViewsDeleted int64 `json:"views_deleted"`
+}
@@ -0,0 +1,12 @@
+package types
+
+// UrlMergeResult summarizes the data moved by merging one URL into another
+type UrlMergeResult struct {
+	FromURL        string `json:"from_url"`
+	IntoURL        string `json:"into_url"`
+	ViewsMoved     int64  `json:"views_moved"`
+	LikesMoved     int64  `json:"likes_moved"`
+	DuplicateLikes int64  `json:"duplicate_likes"` // likes dropped because the client already liked the target URL
+	ViewCount      int64  `json:"view_count"`      // view count added to the target URL
+	LikeCount      int64  `json:"like_count"`      // like count added to the target URL
+}
@@ -0,0 +1,10 @@
+package types
+
+// ViewEvent is a single page view to be recorded along with its attribution dimensions
+type ViewEvent struct {
+	ID       int64
+	URL      string // normalized URL
+	ClientID int64
+	Referrer Referrer
+	Campaign Campaign
+}
@@ -0,0 +1,55 @@
+package types
+
+// Core Web Vitals metrics, stored as their numeric code
+const (
+	VitalLCP  int32 = 1
+	VitalINP  int32 = 2
+	VitalCLS  int32 = 3
+	VitalFCP  int32 = 4
+	VitalTTFB int32 = 5
+)
+
+// VitalMeasurement is a single validated metric value of a page load
+type VitalMeasurement struct {
+	Metric int32 // one of the Vital* constants
+	Bucket int16 // histogram bucket of the value, see core.VitalBucket
+}
+
+// VitalBucketCount is the number of samples of a metric reported by a device class
+// that fell into a histogram bucket
+type VitalBucketCount struct {
+	Metric      int32
+	DeviceClass string
+	Bucket      int16
+	Samples     int64
+}
+
+// VitalPercentiles summarizes the distribution of a metric
+type VitalPercentiles struct {
+	Samples int64   `json:"samples"`
+	P50     float64 `json:"p50"`
+	P75     float64 `json:"p75"`
+	P95     float64 `json:"p95"`
+}
+
+// VitalDeviceStats is the distribution of a metric for a single device class
+type VitalDeviceStats struct {
+	DeviceClass string `json:"device_class"`
+	VitalPercentiles
+}
+
+// VitalMetricStats is the distribution of a metric over all devices and per device class
+type VitalMetricStats struct {
+	Metric string `json:"metric"` // e.g. "LCP"
+	Unit   string `json:"unit"`   // "ms", or "" for CLS
+	VitalPercentiles
+	Devices []VitalDeviceStats `json:"devices"`
+}
+
+// VitalsStatsResponse is returned by the web vitals stats API
+type VitalsStatsResponse struct {
+	URL     string             `json:"url"`
+	From    int64              `json:"from"` // Unix nanoseconds, inclusive
+	To      int64              `json:"to"`   // Unix nanoseconds, exclusive
+	Metrics []VitalMetricStats `json:"metrics"`
+}
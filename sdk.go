@@ -0,0 +1,12 @@
+package telemetry
+
+import _ "embed"
+
+// Browser SDK sources, served by the API under /sdk/
+var (
+	//go:embed internal/api/client.js
+	ClientJS []byte
+
+	//go:embed public/fpid.js
+	FPIDJS []byte
+)